@@ -2,6 +2,7 @@ package turbine
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -49,11 +50,16 @@ func runAgents(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
-	cfg, err := config.Load()
+	cfg, err := loadConfig(repoRoot)
 	if err != nil {
 		return err
 	}
+	return generateAgents(ctx, repoRoot, agentsPrdPath, cfg)
+}
 
+// generateAgents writes AGENTS.md in repoRoot from the PRD at prdPath using the
+// slow model of the configured backend.
+func generateAgents(ctx context.Context, repoRoot, prdPath string, cfg *config.Layered) error {
 	backend, model, variant, err := resolveBackend(cfg, "slow")
 	if err != nil {
 		return err
@@ -66,11 +72,11 @@ func runAgents(cmd *cobra.Command, _ []string) error {
 	}
 
 	generator := agents.New(backend, repoRoot)
-	spinner := ui.NewSpinner(fmt.Sprintf("Generating AGENTS.md from %s (%s, %s)...", agentsPrdPath, backend.Name(), model))
+	spinner := ui.NewSpinner(fmt.Sprintf("Generating AGENTS.md from %s (%s, %s)...", prdPath, backend.Name(), model))
 	cancel := spinner.Start(ctx)
 	defer cancel()
 
-	if err := generator.Generate(ctx, agentsPrdPath, artifacts.Root(), model, variant); err != nil {
+	if err := generator.Generate(ctx, prdPath, artifacts.Root(), model, variant); err != nil {
 		spinner.Fail(fmt.Sprintf("Failed: %v", err))
 		return err
 	}
@@ -0,0 +1,123 @@
+package turbine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+var configShowEffective bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the config layers or the effective config",
+	Long: `Lists the config layers in the order they apply: the global config, the project config at
+.turbine/config.yaml, the selected profile, TURBINE_* environment variables and flags. With --effective, prints the merged
+config as YAML with the layer each value came from as a comment; values without a comment are
+built-in defaults, and empty defaults are omitted.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+func runConfigShow(cmd *cobra.Command, _ []string) error {
+	repoRoot := configRepoRoot(cmd)
+	cfg, err := loadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	if configShowEffective {
+		out, err := cfg.Annotated()
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	}
+
+	printConfigFile(config.LayerGlobal, config.ResolveConfigPath())
+	if repoRoot != "" {
+		printConfigFile(config.LayerProject, filepath.Join(repoRoot, config.ProjectRelPath))
+	}
+	if cfg.Profile != "" {
+		fmt.Printf("%-8s %s %s\n", config.LayerProfile, cfg.Profile, ui.Dim("selected by "+cfg.Source("defaults.profile").String()))
+	}
+	for _, env := range config.EnvVars {
+		if value := os.Getenv(env.Name); value != "" {
+			fmt.Printf("%-8s %s=%s %s\n", config.LayerEnv, env.Name, value, ui.Dim("sets "+env.Key))
+		}
+	}
+	return nil
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config for unknown keys and invalid values",
+	Long: `Loads every config layer and reports each unknown key, value of the wrong type and invalid
+setting (such as zero retry counts or undefined backends) with the file and line it is set on.
+Exits non-zero when the config is invalid.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the config file",
+	Long: `Prints a JSON Schema of the config file for editor completion and validation. The schema is
+also published as docs/turbine.schema.json.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		schema, err := config.Schema()
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(schema))
+		return nil
+	},
+}
+
+func runConfigValidate(cmd *cobra.Command, _ []string) error {
+	repoRoot := configRepoRoot(cmd)
+	if _, err := loadConfig(repoRoot); err != nil {
+		return err
+	}
+	fmt.Printf("%s Config is valid\n", ui.SuccessMarker())
+	return nil
+}
+
+// configRepoRoot returns the repository of the working directory, or "" outside
+// one, where only the global config applies.
+func configRepoRoot(cmd *cobra.Command) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	repoRoot, err := gitx.RepoRoot(cmd.Context(), cwd)
+	if err != nil {
+		return ""
+	}
+	return repoRoot
+}
+
+func printConfigFile(layer config.Layer, path string) {
+	status := ui.Dim("(not found)")
+	if _, err := os.Stat(path); err == nil {
+		status = ""
+	}
+	fmt.Printf("%-8s %s %s\n", layer, path, status)
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd, configValidateCmd, configSchemaCmd)
+	configShowCmd.Flags().BoolVar(&configShowEffective, "effective", false, "Print the merged config and where each value came from")
+}
@@ -0,0 +1,76 @@
+package turbine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+)
+
+func TestConfigShowCmd(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configShowEffective = false
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "config.yaml"), []byte("defaults:\n  retry:\n    strokes: 2\n"), 0644))
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"config", "show"})
+	require.NoError(t, cmd.Execute())
+
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"config", "show", "--effective"})
+	require.NoError(t, cmd.Execute())
+
+	t.Setenv("TURBINE_ROTATIONS", "lots")
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"config", "show", "--effective"})
+	assert.ErrorContains(t, cmd.Execute(), "TURBINE_ROTATIONS")
+}
+
+func TestConfigValidateCmd(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"config", "validate"})
+	require.NoError(t, cmd.Execute())
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "config.yaml"), []byte("defaults:\n  retry:\n    rotatons: 5\n"), 0644))
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"config", "validate"})
+	assert.EqualError(t, cmd.Execute(), "invalid config: .turbine/config.yaml:3: defaults.retry.rotatons: unknown key (did you mean rotations?)")
+}
+
+func TestLoadConfig_RunProfile(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TURBINE_PROFILE", "")
+	globalProfile = ""
+	t.Cleanup(func() { globalProfile = "" })
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "config.yaml"), []byte("profiles:\n  cheap:\n    retry:\n      strokes: 1\n  slow:\n    retry:\n      strokes: 9\n"), 0644))
+	cheap, none := "cheap", ""
+	require.NoError(t, state.Save(repoRoot, &state.RunState{RunID: "run-1", Profile: &cheap}))
+
+	cfg, err := loadConfig(repoRoot)
+	require.NoError(t, err)
+	assert.Equal(t, "cheap", cfg.Profile, "a resumed run keeps its profile")
+	assert.Equal(t, 1, cfg.Defaults.Retry.Strokes)
+	assert.Equal(t, config.LayerRun, cfg.Source("defaults.profile").Layer)
+
+	globalProfile = "slow"
+	cfg, err = loadConfig(repoRoot)
+	require.NoError(t, err)
+	assert.Equal(t, 9, cfg.Defaults.Retry.Strokes, "--profile overrides the run's profile")
+
+	globalProfile = ""
+	t.Setenv("TURBINE_PROFILE", "slow")
+	require.NoError(t, state.Save(repoRoot, &state.RunState{RunID: "run-1", Profile: &none}))
+	cfg, err = loadConfig(repoRoot)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profile, "a run started without a profile keeps none")
+	assert.Equal(t, 3, cfg.Defaults.Retry.Strokes)
+}
@@ -0,0 +1,59 @@
+package turbine
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/doctor"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment Turbine runs in",
+	Long: `Checks git and its identity, the working tree, each configured backend's executable and
+version, the global and project config, the .turbine layout, the ignore rules for run data, and
+the PRD and AGENTS.md. Each check passes, warns or fails with a suggested fix. Exits non-zero
+when any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	results := doctor.Run(cmd.Context(), cwd, globalProfile)
+	counts := map[doctor.Status]int{}
+	for _, r := range results {
+		counts[r.Status]++
+		fmt.Printf("%s %s %s\n", doctorMarker(r.Status), ui.Bold(r.Check), ui.Dim(r.Message))
+		if r.Fix != "" && r.Status != doctor.StatusPass {
+			fmt.Printf("    %s\n", ui.Dim("fix: "+r.Fix))
+		}
+	}
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n", counts[doctor.StatusPass], counts[doctor.StatusWarn], counts[doctor.StatusFail])
+
+	if n := doctor.Failed(results); n > 0 {
+		return fmt.Errorf("%d doctor checks failed", n)
+	}
+	return nil
+}
+
+func doctorMarker(status doctor.Status) string {
+	switch status {
+	case doctor.StatusFail:
+		return ui.FailureMarker()
+	case doctor.StatusWarn:
+		return ui.Yellow("⚠")
+	default:
+		return ui.SuccessMarker()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,22 @@
+package turbine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorCmd(t *testing.T) {
+	setupTestRepo(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "turbine", "turbine.yaml"), []byte("defaults:\n  backend: missing\n"), 0644))
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"doctor"})
+	assert.ErrorContains(t, cmd.Execute(), "doctor checks failed")
+}
@@ -0,0 +1,256 @@
+package turbine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/run"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+const (
+	// initCommitSubject is the subject of the commit made by turbine init.
+	initCommitSubject = "chore: initialize turbine"
+	// initPendingRelPath lists the files created by init --no-commit, one per
+	// line, so the next init commits them.
+	initPendingRelPath = ".turbine/state/init-pending"
+)
+
+var (
+	initPrdPath  string
+	initAgents   bool
+	initNoCommit bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up Turbine in the current repository",
+	Long: `Creates the .turbine layout, a commented project config at .turbine/config.yaml and the
+progress log, and adds the ignore rules for run data to .gitignore. Runs git init first outside a
+repository. With --prd, imports the PRD; with --agents, also generates AGENTS.md from it. The
+files it creates are committed unless --no-commit is given, in which case the next init commits
+them. Other files, tracked or not, are left alone.
+
+Running it again only adds what is missing, so it is safe to repeat. Existing files are kept,
+except that a different PRD given with --prd replaces the imported one after confirmation
+(or with --yes).`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+func runInit(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	// Everything that can fail without changing anything is checked first, so a
+	// bad config or PRD path leaves no half-initialized tree.
+	repoRoot, err := gitx.RepoRoot(ctx, cwd)
+	inRepo := err == nil
+	if !inRepo {
+		repoRoot = ""
+	}
+	cfg, err := loadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	var prdContent []byte
+	if initPrdPath != "" {
+		if prdContent, err = os.ReadFile(initPrdPath); os.IsNotExist(err) {
+			return fmt.Errorf("PRD file not found: %s", initPrdPath)
+		} else if err != nil {
+			return err
+		}
+	}
+	if initAgents && prdContent == nil {
+		if _, err := os.Stat(filepath.Join(repoRoot, run.PRDRelPath)); !inRepo || os.IsNotExist(err) {
+			return fmt.Errorf("PRD file not found: %s (use --prd with --agents)", run.PRDRelPath)
+		}
+	}
+
+	if !inRepo {
+		if repoRoot, err = gitx.Init(ctx, cwd); err != nil {
+			return err
+		}
+		fmt.Printf("%s Initialized git repository %s\n", ui.SuccessMarker(), ui.Dim(repoRoot))
+	}
+	prdDestPath := filepath.Join(repoRoot, run.PRDRelPath)
+
+	// changed lists the files created or updated, relative to repoRoot.
+	var changed []string
+	created := func(relPath string) {
+		changed = append(changed, relPath)
+		fmt.Printf("%s Created %s\n", ui.SuccessMarker(), relPath)
+	}
+
+	for _, dir := range []string{run.RunsDir, filepath.Dir(state.LockRelPath)} {
+		if err := os.MkdirAll(filepath.Join(repoRoot, dir), 0755); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+	}
+
+	configPath := filepath.Join(repoRoot, config.ProjectRelPath)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := os.WriteFile(configPath, []byte(config.ProjectTemplate), 0644); err != nil {
+			return fmt.Errorf("write project config: %w", err)
+		}
+		created(config.ProjectRelPath)
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(repoRoot, run.ProgressRelPath)); os.IsNotExist(err) {
+		if _, err := run.EnsureProgressFile(repoRoot); err != nil {
+			return err
+		}
+		created(run.ProgressRelPath)
+	}
+
+	if prdContent != nil {
+		imported, err := importPRD(prdDestPath, prdContent)
+		if err != nil {
+			return err
+		}
+		if imported {
+			changed = append(changed, run.PRDRelPath)
+			fmt.Printf("%s Imported %s to %s\n", ui.SuccessMarker(), initPrdPath, run.PRDRelPath)
+		}
+	}
+
+	missing, err := gitx.MissingTurbineIgnores(ctx, repoRoot)
+	if err != nil {
+		return fmt.Errorf("check .gitignore: %w", err)
+	}
+	if len(missing) > 0 {
+		if err := gitx.AddIgnoresToGitignore(repoRoot, missing); err != nil {
+			return fmt.Errorf("update .gitignore: %w", err)
+		}
+		changed = append(changed, ".gitignore")
+		fmt.Printf("%s Added %s to .gitignore\n", ui.SuccessMarker(), strings.Join(missing, ", "))
+	}
+
+	if initAgents {
+		if _, err := os.Stat(filepath.Join(repoRoot, "AGENTS.md")); err == nil {
+			fmt.Printf("%s AGENTS.md exists %s\n", ui.SuccessMarker(), ui.Dim("(regenerate with turbine agents)"))
+		} else if err := generateAgents(ctx, repoRoot, prdDestPath, cfg); err != nil {
+			return err
+		} else {
+			changed = append(changed, "AGENTS.md", "CLAUDE.md")
+		}
+	}
+
+	pendingPath := filepath.Join(repoRoot, initPendingRelPath)
+	pending, err := readInitPending(pendingPath)
+	if err != nil {
+		return err
+	}
+	if initNoCommit {
+		if len(changed) == 0 {
+			fmt.Printf("%s Already initialized\n", ui.SuccessMarker())
+			return nil
+		}
+		return writeInitPending(pendingPath, append(pending, changed...))
+	}
+
+	// Files an earlier --no-commit created are committed too, unless they were
+	// committed or removed since.
+	if len(pending) > 0 {
+		uncommitted, err := gitx.ChangedPaths(ctx, repoRoot, pending...)
+		if err != nil {
+			return err
+		}
+		for _, path := range uncommitted {
+			if !slices.Contains(changed, path) {
+				changed = append(changed, path)
+				fmt.Printf("%s Including %s %s\n", ui.SuccessMarker(), path, ui.Dim("(created by init --no-commit)"))
+			}
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Printf("%s Already initialized\n", ui.SuccessMarker())
+		return removeInitPending(pendingPath)
+	}
+	commit := cfg.Defaults.Commit
+	hash, err := gitx.CommitSavePointWithOptions(ctx, repoRoot, initCommitSubject, gitx.CommitOptions{
+		Author:     gitx.Identity{Name: commit.Author.Name, Email: commit.Author.Email},
+		Committer:  gitx.Identity{Name: commit.Committer.Name, Email: commit.Committer.Email},
+		Sign:       commit.Sign,
+		SigningKey: commit.SigningKey,
+		Paths:      changed,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Committed %s %s\n", ui.SuccessMarker(), hash[:7], ui.Dim(initCommitSubject))
+	return removeInitPending(pendingPath)
+}
+
+// readInitPending returns the files recorded by an earlier init --no-commit.
+func readInitPending(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read %s: %w", initPendingRelPath, err)
+	}
+	return strings.Fields(string(data)), nil
+}
+
+func writeInitPending(path string, paths []string) error {
+	slices.Sort(paths)
+	data := strings.Join(slices.Compact(paths), "\n") + "\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", initPendingRelPath, err)
+	}
+	return nil
+}
+
+func removeInitPending(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %s: %w", initPendingRelPath, err)
+	}
+	return nil
+}
+
+// importPRD writes content to the PRD at path and reports whether it changed.
+// A different existing PRD is replaced only after confirmation or with --yes.
+func importPRD(path string, content []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(existing, content):
+		return false, nil
+	case err == nil && !globalYes:
+		fmt.Printf("%s exists. %s [y/N]: ", ui.Dim(path), ui.Yellow("Overwrite?"))
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		if strings.ToLower(scanner.Text()) != "y" {
+			fmt.Printf("%s Kept %s\n", ui.SuccessMarker(), run.PRDRelPath)
+			return false, nil
+		}
+	case err != nil && !os.IsNotExist(err):
+		return false, err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return false, fmt.Errorf("write PRD: %w", err)
+	}
+	return true, nil
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initPrdPath, "prd", "", "Path to a PRD file to import")
+	initCmd.Flags().BoolVar(&initAgents, "agents", false, "Generate AGENTS.md from the PRD")
+	initCmd.Flags().BoolVar(&initNoCommit, "no-commit", false, "Leave the created files uncommitted")
+}
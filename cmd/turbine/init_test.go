@@ -0,0 +1,118 @@
+package turbine
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/run"
+)
+
+func TestInitCmd(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	initPrdPath, initAgents, initNoCommit, globalYes, globalBackend = "", false, false, false, ""
+	t.Cleanup(func() { initPrdPath, initAgents, initNoCommit, globalYes, globalBackend = "", false, false, false, "" })
+
+	scenario := filepath.Join(configHome, "scenario.yaml")
+	require.NoError(t, os.WriteFile(scenario, []byte("agents:\n  files: {AGENTS.md: \"# Agents\\n\"}\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "turbine", "turbine.yaml"), []byte("backends:\n  demo:\n    type: fake\n    scenario: "+scenario+"\n"), 0644))
+	require.NoError(t, os.WriteFile("PRD.md", []byte("# PRD\n"), 0644))
+	// The fake backend writes only AGENTS.md; generation also expects the link.
+	require.NoError(t, os.Symlink("AGENTS.md", "CLAUDE.md"))
+
+	git := func(args ...string) string {
+		out, err := exec.Command("git", args...).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"init", "--prd", "PRD.md", "--agents", "--backend", "demo", "--yes"})
+	require.NoError(t, cmd.Execute())
+
+	projectConfig, err := os.ReadFile(filepath.Join(repoRoot, config.ProjectRelPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(projectConfig), "#profiles:")
+	prd, err := os.ReadFile(filepath.Join(repoRoot, run.PRDRelPath))
+	require.NoError(t, err)
+	assert.Equal(t, "# PRD\n", string(prd))
+	ignores, err := os.ReadFile(filepath.Join(repoRoot, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, ".turbine/runs/\n.turbine/state/\n", string(ignores))
+	assert.Equal(t, "chore: initialize turbine", git("log", "-1", "--pretty=%s"))
+	assert.Equal(t, ".gitignore\n.turbine/config.yaml\n.turbine/prd.md\n.turbine/progress.md\nAGENTS.md\nCLAUDE.md", git("show", "--name-only", "--pretty=", "HEAD"))
+	assert.Equal(t, "?? PRD.md", git("status", "--porcelain"), "files init did not create are left alone")
+
+	head := git("rev-parse", "HEAD")
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"init", "--prd", "PRD.md", "--agents", "--backend", "demo", "--yes"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, head, git("rev-parse", "HEAD"), "a second init changes nothing")
+}
+
+func TestInitCmd_AgentsNeedPRD(t *testing.T) {
+	setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	initPrdPath, initAgents, initNoCommit = "", true, false
+	t.Cleanup(func() { initAgents = false })
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"init", "--agents"})
+	assert.ErrorContains(t, cmd.Execute(), "use --prd with --agents")
+	_, err := os.Stat(config.ProjectRelPath)
+	assert.True(t, os.IsNotExist(err), "nothing is created")
+}
+
+func TestInitCmd_NoCommit(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	initPrdPath, initAgents, initNoCommit, globalYes = "", false, false, false
+	t.Cleanup(func() { initNoCommit = false })
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "AGENTS.md"), []byte("# Mine\n"), 0644))
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"init", "--no-commit"})
+	require.NoError(t, cmd.Execute())
+	assert.FileExists(t, filepath.Join(repoRoot, initPendingRelPath))
+
+	initNoCommit = false
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"init"})
+	require.NoError(t, cmd.Execute())
+
+	out, err := exec.Command("git", "show", "--name-only", "--pretty=", "HEAD").Output()
+	require.NoError(t, err)
+	assert.Equal(t, ".gitignore\n.turbine/config.yaml\n.turbine/progress.md\n", string(out), "files from --no-commit are committed, the user's are not")
+	assert.NoFileExists(t, filepath.Join(repoRoot, initPendingRelPath))
+}
+
+func TestInitCmd_InvalidConfig(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	initPrdPath, initAgents, initNoCommit = "", false, false
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "turbine", "turbine.yaml"), []byte("defaults:\n  retry:\n    rotatons: 5\n"), 0644))
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"init"})
+	assert.ErrorContains(t, cmd.Execute(), "unknown key")
+	assert.NoFileExists(t, filepath.Join(repoRoot, config.ProjectRelPath), "nothing is created")
+	assert.NoFileExists(t, filepath.Join(repoRoot, ".gitignore"))
+}
@@ -0,0 +1,158 @@
+package turbine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/run"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+var (
+	logsTask   string
+	logsStep   string
+	logsKinds  []string
+	logsFollow bool
+)
+
+// logsPollInterval is how often --follow checks for new events.
+var logsPollInterval = 500 * time.Millisecond
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [run-or-workflow-id]",
+	Short: "List runs or show a run's events as a transcript",
+	Long: `Without arguments, lists the runs under .turbine/runs and their workflows. With a run or
+workflow ID, renders the recorded events (agent text, tool calls, usage and errors) as a
+transcript; compressed runs are read from their archives. Filters and --follow without an ID apply to the active or most recent run.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLogs,
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := gitx.RepoRoot(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	activeRunID := ""
+	if runState, exists, err := state.Load(repoRoot); err != nil {
+		return fmt.Errorf("load state: %w", err)
+	} else if exists {
+		activeRunID = runState.RunID
+	}
+
+	runs, err := run.ListRuns(repoRoot, activeRunID)
+	if err != nil {
+		return err
+	}
+
+	filter := run.EventFilter{Task: logsTask, Step: logsStep, Kinds: logsKinds}
+	id := ""
+	if len(args) == 1 {
+		id = args[0]
+	} else if logsFollow || filter.Task != "" || filter.Step != "" || len(filter.Kinds) > 0 {
+		if len(runs) == 0 {
+			return fmt.Errorf("no runs found in %s", run.RunsDir)
+		}
+		id = runs[0].ID
+	}
+
+	if id == "" {
+		return listRunLogs(repoRoot, runs)
+	}
+	return showRunLogs(ctx, repoRoot, id, filter)
+}
+
+func listRunLogs(repoRoot string, runs []run.StoredRun) error {
+	if len(runs) == 0 {
+		fmt.Printf("%s\n", ui.Dim("No runs found."))
+		return nil
+	}
+	for _, r := range runs {
+		details := []string{r.Modified.Local().Format("2006-01-02 15:04"), run.FormatBytes(r.Size)}
+		if r.Active {
+			details = append(details, "active")
+		}
+		if r.Compressed() {
+			details = append(details, "compressed")
+		}
+		fmt.Printf("%s %s\n", ui.Bold(r.ID), ui.Dim(strings.Join(details, ", ")))
+
+		logs, err := run.WorkflowLogs(repoRoot, r.ID)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			fmt.Printf("  %s\n", l.ID)
+		}
+	}
+	return nil
+}
+
+func showRunLogs(ctx context.Context, repoRoot, id string, filter run.EventFilter) error {
+	offsets := map[string]int64{}
+	printed := false
+	lastWorkflow := ""
+
+	for {
+		logs, err := run.WorkflowLogs(repoRoot, id)
+		if err != nil {
+			return err
+		}
+		if len(logs) == 0 && !logsFollow {
+			return fmt.Errorf("no events found for %s", id)
+		}
+
+		for _, l := range logs {
+			events, offset, err := run.ReadEvents(l.Path, offsets[l.ID])
+			if err != nil {
+				return err
+			}
+			offsets[l.ID] = offset
+			for _, evt := range events {
+				if !filter.Match(l.ID, evt) {
+					continue
+				}
+				if l.ID != lastWorkflow {
+					fmt.Printf("%s\n", ui.Section("›", ui.Bold(l.ID)))
+					lastWorkflow = l.ID
+				}
+				fmt.Printf("  %s\n", run.FormatEvent(evt))
+				printed = true
+			}
+		}
+
+		if !logsFollow {
+			if !printed {
+				fmt.Printf("%s\n", ui.Dim("No matching events."))
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logsPollInterval):
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringVar(&logsTask, "task", "", "Only show events of this task ID")
+	logsCmd.Flags().StringVar(&logsStep, "step", "", "Only show events of this step ID")
+	logsCmd.Flags().StringSliceVar(&logsKinds, "kind", nil, "Only show these event kinds (comma-separated)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new events as they are written")
+}
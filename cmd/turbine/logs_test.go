@@ -0,0 +1,35 @@
+package turbine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relaystore "github.com/yarlson/relay/store"
+)
+
+func TestLogsCmd(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	logsTask, logsStep, logsKinds, logsFollow = "", "", nil, false
+
+	workflowDir := filepath.Join(repoRoot, ".turbine", "runs", "20260101-100000-aaaa-T-001")
+	require.NoError(t, os.MkdirAll(workflowDir, 0755))
+	data, err := json.Marshal(relaystore.Event{Kind: "text", Text: "hello"})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(workflowDir, "events.log"), append(data, '\n'), 0644))
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"logs"})
+	require.NoError(t, cmd.Execute())
+
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"logs", "20260101-100000-aaaa", "--kind", "text"})
+	require.NoError(t, cmd.Execute())
+
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"logs", "missing-run"})
+	assert.ErrorContains(t, cmd.Execute(), "no events found")
+}
@@ -0,0 +1,136 @@
+package turbine
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/run"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+var (
+	pruneKeep     int
+	pruneMaxAge   int
+	pruneMaxSize  int
+	pruneCompress bool
+	pruneDryRun   bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete or compress old run artifacts",
+	Long: `Applies the retention policy to .turbine/runs: keeps the most recent runs, deletes runs older
+than the maximum age or beyond the total size budget, and deletes (or with --compress gzips) runs
+beyond the kept count. Limits default to defaults.retention in the config; flags override them.
+The active run is never touched.`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func runPrune(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := gitx.RepoRoot(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	lock, err := state.AcquireLock(repoRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	cfg, err := loadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	policy := cfg.Defaults.Retention
+	flags := cmd.Flags()
+	if flags.Changed("keep") {
+		policy.KeepRuns = pruneKeep
+	}
+	if flags.Changed("max-age") {
+		policy.MaxAgeDays = pruneMaxAge
+	}
+	if flags.Changed("max-size") {
+		policy.MaxSizeMB = pruneMaxSize
+	}
+	if flags.Changed("compress") {
+		policy.Compress = pruneCompress
+	}
+	if !policy.Enabled() {
+		return fmt.Errorf("no retention limits set; use --keep, --max-age or --max-size, or set defaults.retention")
+	}
+
+	activeRunID := ""
+	if runState, exists, err := state.Load(repoRoot); err != nil {
+		return fmt.Errorf("load state: %w", err)
+	} else if exists {
+		activeRunID = runState.RunID
+	}
+
+	runs, err := run.ListRuns(repoRoot, activeRunID)
+	if err != nil {
+		return err
+	}
+	decisions := run.PlanPrune(runs, policy, time.Now())
+	printPruneDecisions(decisions)
+
+	if pruneDryRun {
+		fmt.Printf("%s\n", ui.Dim("Dry run: nothing changed."))
+		return nil
+	}
+
+	reclaimed, err := run.ApplyPrune(decisions)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Reclaimed %s\n", ui.SuccessMarker(), run.FormatBytes(reclaimed))
+	return nil
+}
+
+func printPruneDecisions(decisions []run.PruneDecision) {
+	for _, d := range decisions {
+		line := fmt.Sprintf("%s  %s", d.Run.ID, run.FormatBytes(d.Run.Size))
+		switch d.Action {
+		case run.PruneDelete:
+			fmt.Printf("  %s %s %s\n", ui.FailureMarker(), line, ui.Dim("delete: "+d.Reason))
+		case run.PruneCompress:
+			fmt.Printf("  %s %s %s\n", ui.Yellow("⇣"), line, ui.Dim("compress: "+d.Reason))
+		default:
+			reason := ""
+			if d.Reason != "" {
+				reason = ui.Dim(d.Reason)
+			}
+			fmt.Printf("  %s %s %s\n", ui.SuccessMarker(), line, reason)
+		}
+	}
+}
+
+func countPruned(decisions []run.PruneDecision) int {
+	n := 0
+	for _, d := range decisions {
+		if d.Action != run.PruneKeep {
+			n++
+		}
+	}
+	return n
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().IntVar(&pruneKeep, "keep", 0, "Keep the N most recent runs")
+	pruneCmd.Flags().IntVar(&pruneMaxAge, "max-age", 0, "Delete runs older than N days")
+	pruneCmd.Flags().IntVar(&pruneMaxSize, "max-size", 0, "Delete the oldest runs beyond N MB in total")
+	pruneCmd.Flags().BoolVar(&pruneCompress, "compress", false, "Gzip runs beyond --keep instead of deleting them")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be pruned without changing anything")
+}
@@ -0,0 +1,39 @@
+package turbine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneCmd(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	pruneKeep, pruneMaxAge, pruneMaxSize, pruneCompress, pruneDryRun = 0, 0, 0, false, false
+
+	runsDir := filepath.Join(repoRoot, ".turbine", "runs")
+	for _, name := range []string{"20260101-100000-aaaa", "20260102-100000-bbbb"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(runsDir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(runsDir, name, "events.log"), []byte(name), 0644))
+	}
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"prune"})
+	assert.ErrorContains(t, cmd.Execute(), "no retention limits set")
+
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"prune", "--keep", "1", "--dry-run"})
+	require.NoError(t, cmd.Execute())
+	assert.DirExists(t, filepath.Join(runsDir, "20260101-100000-aaaa"))
+
+	pruneDryRun = false
+	cmd = RootCmd()
+	cmd.SetArgs([]string{"prune", "--keep", "1"})
+	require.NoError(t, cmd.Execute())
+	entries, err := os.ReadDir(runsDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -1,17 +1,25 @@
 package turbine
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	relay "github.com/yarlson/relay"
 	"github.com/yarlson/turbine/internal/config"
 	relayprovider "github.com/yarlson/turbine/internal/relay/provider"
+	"github.com/yarlson/turbine/internal/run"
 )
 
 var (
 	globalBackend string
 	globalModel   string
 	globalVariant string
+	globalProfile string
 	globalYes     bool
+	globalQuiet   bool
+	globalVerbose bool
+	globalDebug   bool
+	globalOutput  string
 )
 
 var rootCmd = &cobra.Command{
@@ -25,26 +33,72 @@ func RootCmd() *cobra.Command {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&globalBackend, "backend", "", "AI backend (opencode, claude)")
+	rootCmd.PersistentFlags().StringVar(&globalBackend, "backend", "", "AI backend (opencode, claude, or a configured backend)")
 	rootCmd.PersistentFlags().StringVar(&globalModel, "model", "", "Model name for the backend")
 	rootCmd.PersistentFlags().StringVar(&globalVariant, "variant", "", "Variant configuration")
+	rootCmd.PersistentFlags().StringVar(&globalProfile, "profile", "", "Config profile to apply (overrides defaults.profile)")
 	rootCmd.PersistentFlags().BoolVar(&globalYes, "yes", false, "Skip confirmation prompts")
+	rootCmd.PersistentFlags().BoolVarP(&globalQuiet, "quiet", "q", false, "Print only warnings and errors")
+	rootCmd.PersistentFlags().BoolVarP(&globalVerbose, "verbose", "v", false, "Stream agent output during strokes")
+	rootCmd.PersistentFlags().BoolVar(&globalDebug, "debug", false, "Stream every backend event (implies --verbose)")
+	rootCmd.PersistentFlags().StringVar(&globalOutput, "output", "text", "Output format (text, json)")
+}
+
+// outputMode merges defaults.quiet with the output flags.
+func outputMode(cfg *config.Config) (run.Output, error) {
+	eff := config.Merge(cfg, config.Overrides{
+		Quiet:   globalQuiet,
+		Verbose: globalVerbose,
+		Debug:   globalDebug,
+	})
+	out := run.Output{Quiet: eff.Quiet, Verbose: eff.Verbose, Debug: eff.Debug}
+	switch globalOutput {
+	case "", "text":
+	case "json":
+		out.JSON = true
+	default:
+		return run.Output{}, fmt.Errorf("invalid --output %q (expected: text, json)", globalOutput)
+	}
+	return out, nil
 }
 
-func resolveBackend(cfg *config.Config, defaultModel string) (relay.Provider, string, string, error) {
-	return relayprovider.Resolve(cfg, config.Overrides{
+// loadConfig loads the config layers for the repository at repoRoot (global
+// only when empty) and applies the flags over them. Without --profile, an
+// unfinished run keeps the profile it was started with.
+func loadConfig(repoRoot string) (*config.Layered, error) {
+	opts, err := run.ConfigOptions(repoRoot, globalProfile)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadLayered(repoRoot, opts)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ApplyFlags(config.Overrides{
 		Backend: globalBackend,
 		Model:   globalModel,
 		Variant: globalVariant,
-	}, defaultModel)
+		Quiet:   globalQuiet,
+	})
+	return cfg, nil
+}
+
+func resolveBackend(cfg *config.Layered, defaultModel string) (relay.Provider, string, string, error) {
+	return relayprovider.Resolve(cfg.Config, cfg.Overrides(), defaultModel)
 }
 
 // resolveBackendWithModels returns a backend and both fast/slow model configs.
 // Used for two-phase operations like planning that need both models.
-func resolveBackendWithModels(cfg *config.Config) (relay.Provider, config.Model, config.Model, error) {
-	return relayprovider.ResolveWithModels(cfg, config.Overrides{
-		Backend: globalBackend,
-		Model:   globalModel,
-		Variant: globalVariant,
-	})
+func resolveBackendWithModels(cfg *config.Layered) (relay.Provider, config.Model, config.Model, error) {
+	return relayprovider.ResolveWithModels(cfg.Config, cfg.Overrides())
+}
+
+// backendByName returns the configured backend named name and its models. The
+// runner uses it to switch backends for escalation and failover.
+func backendByName(cfg *config.Config, name string) (relay.Provider, run.Models, error) {
+	p, fast, slow, err := relayprovider.ResolveWithModels(cfg, config.Overrides{Backend: name})
+	if err != nil {
+		return nil, run.Models{}, err
+	}
+	return p, run.Models{Fast: fast, Slow: slow}, nil
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/turbine/internal/config"
 )
 
 func TestRootCmd(t *testing.T) {
@@ -11,3 +13,17 @@ func TestRootCmd(t *testing.T) {
 	assert.Equal(t, "turbine", cmd.Use)
 	assert.NotNil(t, cmd)
 }
+
+func TestOutputMode(t *testing.T) {
+	t.Cleanup(func() { globalOutput = "text" })
+	cfg := config.DefaultConfig()
+
+	globalOutput = "json"
+	out, err := outputMode(cfg)
+	require.NoError(t, err)
+	assert.True(t, out.JSON)
+
+	globalOutput = "yaml"
+	_, err = outputMode(cfg)
+	assert.ErrorContains(t, err, "invalid --output")
+}
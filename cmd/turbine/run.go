@@ -8,17 +8,26 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/yarlson/turbine/internal/config"
+	relay "github.com/yarlson/relay"
 	"github.com/yarlson/turbine/internal/gitx"
+	relayprovider "github.com/yarlson/turbine/internal/relay/provider"
 	"github.com/yarlson/turbine/internal/run"
 	"github.com/yarlson/turbine/internal/ui"
 )
 
-var runPrdPath string
+var (
+	runPrdPath string
+	runRecord  bool
+	runReplay  string
+)
 
 func runCmd(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	if runRecord && runReplay != "" {
+		return fmt.Errorf("--record and --replay cannot be combined")
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return err
@@ -28,6 +37,17 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := loadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	output, err := outputMode(cfg.Config)
+	if err != nil {
+		return err
+	}
+	// In JSON mode stdout carries only lifecycle events.
+	w := output.HumanWriter()
+
 	prdDestPath := filepath.Join(repoRoot, run.PRDRelPath)
 	if runPrdPath != "" {
 		if _, err := os.Stat(runPrdPath); os.IsNotExist(err) {
@@ -41,7 +61,7 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		}
 
 		if _, err := os.Stat(prdDestPath); err == nil && !globalYes {
-			fmt.Printf("%s exists. %s [y/N]: ", ui.Dim(prdDestPath), ui.Yellow("Overwrite?"))
+			fmt.Fprintf(w, "%s exists. %s [y/N]: ", ui.Dim(prdDestPath), ui.Yellow("Overwrite?"))
 			scanner := bufio.NewScanner(os.Stdin)
 			scanner.Scan()
 			resp := scanner.Text()
@@ -65,28 +85,78 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		return err
+	// Backends switched to by escalation and failover are recorded and replayed
+	// like the run's own backend.
+	var (
+		recorder *relayprovider.Recorder
+		replay   *relayprovider.ReplayProvider
+	)
+	backends := func(name string) (relay.Provider, run.Models, error) {
+		if replay != nil {
+			b, ok := cfg.Backends[name]
+			if !ok {
+				return nil, run.Models{}, fmt.Errorf("unknown backend: %s", name)
+			}
+			return replay.As(name), run.Models{Fast: b.Models.Fast, Slow: b.Models.Slow}, nil
+		}
+		p, models, err := backendByName(cfg.Config, name)
+		if err != nil || recorder == nil {
+			return p, models, err
+		}
+		return recorder.Wrap(p), models, nil
 	}
 
 	r, err := run.NewRunner(ctx, run.Config{
 		AutoAddIgnore: globalYes,
 		Defaults:      cfg.Defaults,
+		Output:        output,
+		Profile:       cfg.Profile,
+		Backends:      backends,
 	})
 	if err != nil {
 		return err
 	}
+	defer func() { _ = r.Close() }()
+
+	// Load the recording before pruning so an old replayed run is not removed first.
+	if runReplay != "" {
+		if replay, err = relayprovider.NewReplay(r.RepoRoot, runReplay); err != nil {
+			return err
+		}
+	}
+
+	if policy := cfg.Defaults.Retention; policy.Enabled() {
+		decisions, reclaimed, err := run.Prune(r.RepoRoot, r.State.RunID, policy)
+		if err != nil {
+			return fmt.Errorf("prune runs: %w", err)
+		}
+		if pruned := countPruned(decisions); pruned > 0 && !output.Quiet {
+			fmt.Fprintf(w, "%s\n", ui.Dim(fmt.Sprintf("Pruned %d old run(s), reclaimed %s", pruned, run.FormatBytes(reclaimed))))
+		}
+	}
 
 	backend, fastModel, slowModel, err := resolveBackendWithModels(cfg)
 	if err != nil {
 		return err
 	}
+	switch {
+	case replay != nil:
+		backend = replay
+	case runRecord:
+		recorder = relayprovider.NewRecorder(backend, r.RepoRoot, r.State.RunID)
+		backend = recorder
+	}
 
-	fmt.Printf("Using backend: %s, fast: %s, slow: %s\n", backend.Name(), fastModel.Name, slowModel.Name)
-
-	if r.Resume {
-		fmt.Printf("Continuing from checkpoint: %s\n", r.State.RunID)
+	if !output.Quiet {
+		fmt.Fprintf(w, "Using backend: %s, fast: %s, slow: %s\n", backend.Name(), fastModel.Name, slowModel.Name)
+		if replay != nil {
+			fmt.Fprintf(w, "Replaying recorded run: %s\n", runReplay)
+		} else if runRecord {
+			fmt.Fprintf(w, "Recording to: %s\n", ui.Dim(relayprovider.RecordingDir(r.RepoRoot, r.State.RunID)))
+		}
+		if r.Resume {
+			fmt.Fprintf(w, "Continuing from checkpoint: %s\n", r.State.RunID)
+		}
 	}
 
 	return r.Run(ctx, backend, run.Models{Fast: fastModel, Slow: slowModel})
@@ -95,4 +165,6 @@ func runCmd(cmd *cobra.Command, args []string) error {
 func init() {
 	rootCmd.RunE = runCmd
 	rootCmd.Flags().StringVar(&runPrdPath, "prd", "", "Path to the PRD file")
+	rootCmd.Flags().BoolVar(&runRecord, "record", false, "Record backend calls and file changes for replay")
+	rootCmd.Flags().StringVar(&runReplay, "replay", "", "Replay the recorded backend calls of a run instead of calling the backend")
 }
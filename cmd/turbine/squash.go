@@ -0,0 +1,107 @@
+package turbine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/run"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+var (
+	squashRunID     string
+	squashBySection bool
+	squashMessage   string
+)
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Squash a run's commits into one commit or one per PRD section",
+	Long: `Finds all commits of a run (by their Turbine-Run trailer) and rewrites them locally into a
+single commit, or with --by-section into one commit per consecutive PRD section. Messages are
+generated from the task archive and must pass the commit message policy; savepoint notes move to
+the new commits. Only unpushed history is rewritten and the previous history is kept under
+refs/turbine/backup/.`,
+	Args: cobra.NoArgs,
+	RunE: runSquash,
+}
+
+func runSquash(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := gitx.RepoRoot(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	lock, err := state.AcquireLock(repoRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	dirty, err := gitx.IsDirtyExcluding(ctx, repoRoot, ".turbine")
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("uncommitted changes detected; save your progress before squash")
+	}
+
+	plan, err := run.PlanSquash(ctx, repoRoot, squashRunID)
+	if err != nil {
+		return err
+	}
+	groups := plan.Groups(repoRoot, squashBySection)
+
+	fmt.Printf("%s\n", ui.Section("⇣", fmt.Sprintf("Squash run %s: %d commits → %d", plan.RunID, len(plan.Commits), len(groups))))
+	for _, g := range groups {
+		title := g.Section
+		if title == "" {
+			title = "(all tasks)"
+		}
+		fmt.Printf("  %s\n", ui.Bold(title))
+		for _, c := range g.Commits {
+			fmt.Printf("    %s\n", ui.TaskEntry(c.Trailers[run.TrailerTask], c.Subject))
+		}
+	}
+
+	if !globalYes {
+		fmt.Printf("%s [y/N]: ", ui.Yellow("Rewrite local history?"))
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		if strings.ToLower(scanner.Text()) != "y" {
+			return fmt.Errorf("canceled")
+		}
+	}
+
+	cfg, err := loadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	backupRef, err := run.ApplySquash(ctx, repoRoot, plan, groups, squashMessage, cfg.Defaults.Commit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Squashed into %d commit(s)\n", ui.SuccessMarker(), len(groups))
+	fmt.Printf("  %s\n", ui.Dim(fmt.Sprintf("Backup: %s (restore with: git reset --hard %s)", backupRef, backupRef)))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(squashCmd)
+	squashCmd.Flags().StringVar(&squashRunID, "run", "", "Run ID to squash (defaults to the most recent run)")
+	squashCmd.Flags().BoolVar(&squashBySection, "by-section", false, "Create one commit per consecutive PRD section")
+	squashCmd.Flags().StringVarP(&squashMessage, "message", "m", "", "Subject for the squashed commit (single commit only)")
+}
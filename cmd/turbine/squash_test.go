@@ -0,0 +1,27 @@
+package turbine
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquashCmd(t *testing.T) {
+	repoRoot := setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	commitTestFile(t, repoRoot, "base.txt", "chore: base")
+	commitTestFile(t, repoRoot, "a.txt", "feat: a\n\nTurbine: T-001\nTurbine-Run: run-1")
+	commitTestFile(t, repoRoot, "b.txt", "feat: b\n\nTurbine: T-002\nTurbine-Run: run-1")
+	squashRunID, squashBySection, squashMessage = "", false, ""
+
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"squash", "--run", "run-1", "-m", "feat: a and b", "--yes"})
+	require.NoError(t, cmd.Execute())
+
+	out, err := exec.Command("git", "-C", repoRoot, "log", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feat: a and b", "chore: base"}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+}
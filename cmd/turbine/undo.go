@@ -0,0 +1,120 @@
+package turbine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/run"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+var (
+	undoForce  bool
+	undoRevert bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [N]",
+	Short: "Roll back the last N Turbine tasks",
+	Long: `Finds the last N Turbine savepoint commits (by their "Turbine:" footer) and rolls them back.
+Commits on top of history are removed with a hard reset; otherwise revert commits are created.
+Revert commits carry a "Turbine-Reverts:" footer, and the savepoints they revert are skipped later.
+Rolled-back tasks are marked as reverted in the progress log so they are planned again.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUndo,
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	n := 1
+	if len(args) == 1 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of tasks: %s", args[0])
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := gitx.RepoRoot(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	lock, err := state.AcquireLock(repoRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	dirty, err := gitx.IsDirtyExcluding(ctx, repoRoot, ".turbine")
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("uncommitted changes detected; save your progress before undo")
+	}
+
+	cfg, err := loadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	plan, err := run.PlanUndo(ctx, repoRoot, n)
+	if err != nil {
+		return err
+	}
+
+	revert := undoRevert
+	if !plan.OnTop() {
+		if !undoForce {
+			fmt.Printf("%s\n", ui.Section("⚠", "Unrelated commits between Turbine commits"))
+			for _, c := range plan.Interleaved {
+				fmt.Printf("  %s %s\n", ui.Dim(c.Hash[:8]), c.Subject)
+			}
+			return fmt.Errorf("refusing to undo across unrelated commits (use --force to create revert commits)")
+		}
+		revert = true
+	}
+
+	action := "Reset"
+	if revert {
+		action = "Revert"
+	}
+	fmt.Printf("%s\n", ui.Section("⟲", fmt.Sprintf("%s %d Turbine task(s)", action, len(plan.Savepoints))))
+	for _, c := range plan.Savepoints {
+		fmt.Printf("  %s\n", ui.TaskEntry(c.Trailers[run.TrailerTask], fmt.Sprintf("%s %s", ui.Dim(c.Hash[:8]), c.Subject)))
+	}
+
+	if !globalYes {
+		fmt.Printf("%s [y/N]: ", ui.Yellow("Proceed?"))
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		if strings.ToLower(scanner.Text()) != "y" {
+			return fmt.Errorf("canceled")
+		}
+	}
+
+	if err := run.ApplyUndo(ctx, repoRoot, plan, revert, cfg.Defaults.Commit); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", ui.SuccessMarker(), fmt.Sprintf("Rolled back %d task(s)", len(plan.Savepoints)))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Create revert commits when unrelated commits are interleaved")
+	undoCmd.Flags().BoolVar(&undoRevert, "revert", false, "Always create revert commits instead of resetting")
+}
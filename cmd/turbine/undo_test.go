@@ -0,0 +1,69 @@
+package turbine
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitTestFile(t *testing.T, repoRoot, name, msg string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, name), []byte(name), 0644))
+	for _, args := range [][]string{{"add", name}, {"commit", "-m", msg, "--no-gpg-sign"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoRoot
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test User",
+			"GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test User",
+			"GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, string(out))
+	}
+}
+
+func TestUndoCmd(t *testing.T) {
+	t.Run("invalid count", func(t *testing.T) {
+		setupTestRepo(t)
+		undoForce, undoRevert, globalYes = false, false, true
+
+		cmd := RootCmd()
+		cmd.SetArgs([]string{"undo", "zero", "--yes"})
+		err := cmd.Execute()
+		assert.ErrorContains(t, err, "invalid number of tasks")
+	})
+
+	t.Run("refuses interleaved commits without force", func(t *testing.T) {
+		repoRoot := setupTestRepo(t)
+		commitTestFile(t, repoRoot, "a.txt", "feat: a\n\nTurbine: T-001")
+		commitTestFile(t, repoRoot, "b.txt", "chore: manual")
+		undoForce, undoRevert = false, false
+
+		cmd := RootCmd()
+		cmd.SetArgs([]string{"undo", "--yes"})
+		err := cmd.Execute()
+		assert.ErrorContains(t, err, "use --force")
+	})
+
+	t.Run("resets top savepoint", func(t *testing.T) {
+		repoRoot := setupTestRepo(t)
+		commitTestFile(t, repoRoot, "base.txt", "chore: base")
+		commitTestFile(t, repoRoot, "a.txt", "feat: a\n\nTurbine: T-001")
+		undoForce, undoRevert = false, false
+
+		cmd := RootCmd()
+		cmd.SetArgs([]string{"undo", "1", "--yes"})
+		require.NoError(t, cmd.Execute())
+
+		assert.NoFileExists(t, filepath.Join(repoRoot, "a.txt"))
+		progress, err := os.ReadFile(filepath.Join(repoRoot, ".turbine", "progress.md"))
+		require.NoError(t, err)
+		assert.True(t, strings.Contains(string(progress), "T-001 feat: a - reverted"))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	relayprovider "github.com/yarlson/turbine/internal/relay/provider"
 )
 
 func TestBuildAgentsPrompt(t *testing.T) {
@@ -18,3 +19,8 @@ func TestBuildAgentsPrompt(t *testing.T) {
 	assert.Contains(t, result, "Do NOT include code snippets")
 	assert.Contains(t, result, "Command examples are OK")
 }
+
+func TestBuildAgentsPrompt_Phase(t *testing.T) {
+	// The fake backend recognizes AGENTS.md generation by its role prompt.
+	assert.Equal(t, relayprovider.PhaseAgents, relayprovider.Phase(buildAgentsPrompt("prd")))
+}
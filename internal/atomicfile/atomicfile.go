@@ -0,0 +1,183 @@
+// Package atomicfile writes files crash-safely and recovers them from backup copies.
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Recovery describes a file restored from its backup copy.
+type Recovery struct {
+	Path       string
+	BackupPath string
+	Cause      error
+}
+
+func (r Recovery) String() string {
+	return fmt.Sprintf("%s was unreadable (%v); recovered the last good copy from %s", r.Path, r.Cause, r.BackupPath)
+}
+
+var (
+	handlerMu sync.Mutex
+	handler   = func(r Recovery) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", r)
+	}
+)
+
+// SetRecoveryHandler replaces the function that reports recoveries and returns the
+// previous one. By default recoveries are printed to stderr.
+func SetRecoveryHandler(fn func(Recovery)) func(Recovery) {
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	previous := handler
+	handler = fn
+	return previous
+}
+
+func report(r Recovery) {
+	handlerMu.Lock()
+	fn := handler
+	handlerMu.Unlock()
+	if fn != nil {
+		fn(r)
+	}
+}
+
+// WriteFile writes data to path by writing a temporary file in the same directory,
+// syncing it and renaming it into place, so readers see either the old or the new
+// content. When backupPath is set and path already exists, the current content is
+// first copied to backupPath the same way, unless check rejects it: a corrupt file
+// never replaces the last good backup.
+func WriteFile(path string, data []byte, perm os.FileMode, backupPath string, check func([]byte) error) error {
+	if backupPath != "" {
+		current, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if check != nil && check(current) != nil {
+				break
+			}
+			if err := writeAtomic(backupPath, current, perm); err != nil {
+				return fmt.Errorf("write backup: %w", err)
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("read current file: %w", err)
+		}
+	}
+	return writeAtomic(path, data, perm)
+}
+
+type unrecoverableError struct {
+	err error
+}
+
+func (e unrecoverableError) Error() string { return e.err.Error() }
+func (e unrecoverableError) Unwrap() error { return e.err }
+
+// Unrecoverable marks a decode error for a file that is intact but unusable, such
+// as one written by a newer version. ReadFile returns it without trying the backup.
+func Unrecoverable(err error) error {
+	return unrecoverableError{err: err}
+}
+
+// ReadFile reads path and passes its content to decode. If decode fails and
+// backupPath holds content that decodes, the backup is restored over path, the
+// recovery is reported and ReadFile succeeds. A missing path is returned as is,
+// so callers can test it with errors.Is(err, fs.ErrNotExist).
+func ReadFile(path, backupPath string, decode func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decodeErr := decode(data)
+	if decodeErr == nil || backupPath == "" {
+		return decodeErr
+	}
+	var unrecoverable unrecoverableError
+	if errors.As(decodeErr, &unrecoverable) {
+		return unrecoverable.err
+	}
+
+	backup, err := os.ReadFile(backupPath)
+	if err != nil {
+		return decodeErr
+	}
+	if err := decode(backup); err != nil {
+		return decodeErr
+	}
+
+	info, err := os.Stat(path)
+	perm := os.FileMode(0644)
+	if err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := writeAtomic(path, backup, perm); err != nil {
+		return fmt.Errorf("restore %s from backup: %w", path, err)
+	}
+	report(Recovery{Path: path, BackupPath: backupPath, Cause: decodeErr})
+	return nil
+}
+
+// Remove deletes path and its backup copy, ignoring files that do not exist.
+func Remove(path, backupPath string) error {
+	for _, p := range []string{path, backupPath} {
+		if p == "" {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes a directory entry update to disk. It is best effort: some
+// platforms (notably Windows) do not support syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,126 @@
+package atomicfile
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJSON(dest *map[string]int) func([]byte) error {
+	return func(data []byte) error {
+		return json.Unmarshal(data, dest)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "data.json")
+	backup := filepath.Join(dir, "data.json.bak")
+
+	require.NoError(t, WriteFile(path, []byte(`{"a":1}`), 0644, backup, nil))
+	assert.NoFileExists(t, backup, "no backup for a new file")
+
+	require.NoError(t, WriteFile(path, []byte(`{"a":2}`), 0644, backup, nil))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2}`, string(content))
+	content, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temp files are cleaned up")
+
+	// A corrupt current file does not replace the last good backup.
+	var v map[string]int
+	require.NoError(t, os.WriteFile(path, []byte(`{"a":`), 0644))
+	require.NoError(t, WriteFile(path, []byte(`{"a":3}`), 0644, backup, decodeJSON(&v)))
+	content, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(content))
+	require.NoError(t, WriteFile(path, []byte(`{"a":4}`), 0644, backup, decodeJSON(&v)))
+	content, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":3}`, string(content))
+}
+
+func TestReadFile(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		dir := t.TempDir()
+		var v map[string]int
+		err := ReadFile(filepath.Join(dir, "data.json"), filepath.Join(dir, "data.json.bak"), decodeJSON(&v))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("recovers truncated file from backup", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "data.json")
+		backup := filepath.Join(dir, "data.json.bak")
+		require.NoError(t, os.WriteFile(path, []byte(`{"a":`), 0644))
+		require.NoError(t, os.WriteFile(backup, []byte(`{"a":1}`), 0644))
+
+		var recovered []Recovery
+		previous := SetRecoveryHandler(func(r Recovery) { recovered = append(recovered, r) })
+		defer SetRecoveryHandler(previous)
+
+		var v map[string]int
+		require.NoError(t, ReadFile(path, backup, decodeJSON(&v)))
+		assert.Equal(t, 1, v["a"])
+		require.Len(t, recovered, 1)
+		assert.Equal(t, path, recovered[0].Path)
+		assert.Contains(t, recovered[0].String(), "recovered the last good copy")
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(content), "backup restored over the broken file")
+	})
+
+	t.Run("broken file without usable backup", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "data.json")
+		backup := filepath.Join(dir, "data.json.bak")
+		require.NoError(t, os.WriteFile(path, []byte(`{"a":`), 0644))
+
+		var v map[string]int
+		assert.Error(t, ReadFile(path, backup, decodeJSON(&v)))
+
+		require.NoError(t, os.WriteFile(backup, []byte(`nope`), 0644))
+		assert.Error(t, ReadFile(path, backup, decodeJSON(&v)))
+	})
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	backup := filepath.Join(dir, "data.json.bak")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(backup, []byte("{}"), 0644))
+
+	require.NoError(t, Remove(path, backup))
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, backup)
+	require.NoError(t, Remove(path, backup))
+}
+
+func TestReadFileUnrecoverable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	backup := filepath.Join(dir, "data.json.bak")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a":2}`), 0644))
+	require.NoError(t, os.WriteFile(backup, []byte(`{"a":1}`), 0644))
+
+	errTooNew := errors.New("too new")
+	err := ReadFile(path, backup, func([]byte) error { return Unrecoverable(errTooNew) })
+	assert.Same(t, errTooNew, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2}`, string(content), "file is left untouched")
+}
@@ -3,27 +3,190 @@ package config
 import (
 	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v3"
+	"time"
 )
 
 // Config represents the global configuration for turbine.
 type Config struct {
 	Defaults Defaults           `yaml:"defaults"`
 	Backends map[string]Backend `yaml:"backends"`
+	// Profiles are named sets of overrides, selected with --profile or
+	// defaults.profile.
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile overrides the config when selected. Keys it does not set keep their
+// values.
+type Profile struct {
+	Backend  string   `yaml:"backend"`  // Overrides defaults.backend
+	Models   Models   `yaml:"models"`   // Overrides the models of the profile's backend
+	Retry    Retry    `yaml:"retry"`    // Overrides defaults.retry
+	Defaults Defaults `yaml:"defaults"` // Overrides any other default
 }
 
 // Defaults holds default settings for turbine.
 type Defaults struct {
 	Backend string `yaml:"backend"`
+	// Profile names the profile applied when --profile is not given.
+	Profile string `yaml:"profile"`
 	Quiet   bool   `yaml:"quiet"`
 	Retry   Retry  `yaml:"retry"`
+	Commit  Commit `yaml:"commit"`
+	// Retention limits the run artifacts kept under .turbine/runs.
+	Retention Retention `yaml:"retention"`
+	// Budget stops a run once its backend usage reaches a limit.
+	Budget Budget `yaml:"budget"`
+	// Notify lists the notifiers triggered by run lifecycle events.
+	Notify []Notifier `yaml:"notify"`
+	// Hooks run shell commands at fixed points of a run.
+	Hooks Hooks `yaml:"hooks"`
+	// Escalation picks the backend and model of each rotation of a task. The last
+	// rung repeats for later rotations. Empty runs every rotation on the run's
+	// backend with its fast model.
+	Escalation []Rung `yaml:"escalation"`
+	// Failover lists the backends switched to, in order, when a stroke fails with
+	// a provider error. A switch lasts for the rest of the run.
+	Failover []string `yaml:"failover"`
+}
+
+// Rung is one step of the escalation ladder.
+type Rung struct {
+	Backend string `yaml:"backend"` // Backend name; the run's backend when empty
+	Model   string `yaml:"model"`   // "fast" (default), "slow" or a model name
+	Variant string `yaml:"variant"` // Overrides the model's variant
 }
 
 // Retry holds retry configuration.
 type Retry struct {
 	Rotations int `yaml:"rotations"`
 	Strokes   int `yaml:"strokes"`
+	// Transient bounds the retries of rate-limited, overloaded and network
+	// backend failures. These retries do not use up strokes.
+	Transient Backoff `yaml:"transient"`
+}
+
+// Backoff is an exponential backoff policy. Zero values use the defaults.
+type Backoff struct {
+	Attempts int           `yaml:"attempts"` // Retries per stroke (default 5; negative disables)
+	Initial  time.Duration `yaml:"initial"`  // First delay, doubled on each retry (default 2s)
+	Max      time.Duration `yaml:"max"`      // Delay cap (default 1m)
+}
+
+// Default backoff policy for transient backend failures.
+const (
+	DefaultBackoffAttempts = 5
+	DefaultBackoffInitial  = 2 * time.Second
+	DefaultBackoffMax      = time.Minute
+)
+
+// MaxAttempts returns the number of retries allowed per stroke.
+func (b Backoff) MaxAttempts() int {
+	switch {
+	case b.Attempts == 0:
+		return DefaultBackoffAttempts
+	case b.Attempts < 0:
+		return 0
+	}
+	return b.Attempts
+}
+
+// Delay returns the wait before retry number attempt, starting at 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay, limit := b.Initial, b.Max
+	if delay == 0 {
+		delay = DefaultBackoffInitial
+	}
+	if limit == 0 {
+		limit = DefaultBackoffMax
+	}
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// Retention holds the run artifact retention policy. Zero values disable a limit.
+// When any limit is set it is applied automatically at the start of every run.
+type Retention struct {
+	KeepRuns   int  `yaml:"keep_runs"`    // Keep the N most recent runs
+	MaxAgeDays int  `yaml:"max_age_days"` // Delete runs older than this
+	MaxSizeMB  int  `yaml:"max_size_mb"`  // Delete the oldest runs beyond this total size
+	Compress   bool `yaml:"compress"`     // Gzip runs beyond keep_runs instead of deleting them
+}
+
+// Enabled reports whether any retention limit is set.
+func (r Retention) Enabled() bool {
+	return r.KeepRuns > 0 || r.MaxAgeDays > 0 || r.MaxSizeMB > 0
+}
+
+// Budget holds per-run usage limits. Zero values disable a limit. A run that
+// reaches a limit stops before its next stroke and can be resumed later.
+type Budget struct {
+	MaxCostUSD float64 `yaml:"max_cost_usd"` // Total backend cost across all tasks
+	MaxTokens  int     `yaml:"max_tokens"`   // Total input and output tokens across all tasks
+}
+
+// Exceeded reports whether the given usage reaches a limit.
+func (b Budget) Exceeded(tokens int, costUSD float64) bool {
+	return (b.MaxTokens > 0 && tokens >= b.MaxTokens) || (b.MaxCostUSD > 0 && costUSD >= b.MaxCostUSD)
+}
+
+// Notifier sends run lifecycle events to a webhook, a shell command, or both.
+type Notifier struct {
+	// Webhook receives each event as a JSON POST body.
+	Webhook string `yaml:"webhook"`
+	// Headers are added to webhook requests; values are expanded from the environment.
+	Headers map[string]string `yaml:"headers"`
+	// Command runs with /bin/sh, receiving the event as TURBINE_* variables and JSON on stdin.
+	Command string `yaml:"command"`
+	// Events limits the notifier to task_done, task_failed, run_finished or budget_exceeded (all when empty).
+	Events []string `yaml:"events"`
+	// TimeoutSeconds bounds each delivery (default 30).
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+// Hooks holds the shell commands run at each point of a run. Commands run in the
+// repository root in order. Gating hooks (pre_plan, pre_task, pre_verify,
+// pre_commit) stop at the first failure; the others only warn.
+type Hooks struct {
+	PrePlan    []string `yaml:"pre_plan"`    // Before the planner writes the next task; failure stops the run
+	PreTask    []string `yaml:"pre_task"`    // Before a task starts or resumes; failure stops the run
+	PostStroke []string `yaml:"post_stroke"` // After every stroke, passed or failed
+	PreVerify  []string `yaml:"pre_verify"`  // Before verification; failure fails the stroke
+	PreCommit  []string `yaml:"pre_commit"`  // After verification passes; failure fails the stroke instead of committing
+	PostCommit []string `yaml:"post_commit"` // After the savepoint commit
+}
+
+// Commit holds savepoint commit metadata settings.
+type Commit struct {
+	// Trailers lists the metadata trailers appended after the "Turbine:" footer.
+	// Supported: run, backend, model, rotation, stroke, tokens, cost.
+	Trailers []string `yaml:"trailers"`
+	// Notes attaches the task YAML and verification summary as a git note.
+	Notes bool `yaml:"notes"`
+	// Author and Committer override the git identity used for savepoint commits.
+	// Committer defaults to Author when unset.
+	Author    Identity `yaml:"author"`
+	Committer Identity `yaml:"committer"`
+	// Sign selects commit signing: "" (follow git config), "gpg", "ssh" or "off".
+	Sign       string        `yaml:"sign"`
+	SigningKey string        `yaml:"signing_key"`
+	Message    CommitMessage `yaml:"message"`
+}
+
+// Identity holds a git author or committer identity.
+type Identity struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+}
+
+// CommitMessage holds the commit message policy enforced on planned tasks.
+type CommitMessage struct {
+	Conventional bool     `yaml:"conventional"` // Require Conventional Commits subjects
+	Types        []string `yaml:"types"`        // Allowed types (defaults to the standard set)
+	Scopes       []string `yaml:"scopes"`       // Allowed scopes (any when empty)
+	MaxSubject   int      `yaml:"max_subject"`  // Max subject length (0 = unlimited)
+	Body         bool     `yaml:"body"`         // Add task description and acceptance criteria to the body
 }
 
 // Model holds a model name and optional variant.
@@ -61,9 +224,40 @@ type Models struct {
 
 // Backend holds configuration for a specific agent backend.
 type Backend struct {
+	// Type selects the implementation: opencode, claude, exec or fake. Defaults to the backend's name.
+	Type    string   `yaml:"type"`
 	Command string   `yaml:"command"`
 	Args    []string `yaml:"args"`
 	Models  Models   `yaml:"models"`
+	// Exec describes how to drive an arbitrary agent CLI (type exec only).
+	Exec Exec `yaml:"exec"`
+	// Scenario is the YAML file played by a scripted backend (type fake only).
+	Scenario string `yaml:"scenario"`
+}
+
+// Exec holds argument templates and output parsing for a generic agent CLI.
+// Each template is a list of arguments appended after Args when its value is
+// set; {prompt}, {model}, {variant}, {workdir} and {session} are replaced.
+type Exec struct {
+	Prompt   []string   `yaml:"prompt"`   // Arguments passing the prompt; stdin when empty
+	Model    []string   `yaml:"model"`    // Arguments selecting the model
+	Variant  []string   `yaml:"variant"`  // Arguments selecting the variant
+	WorkDir  []string   `yaml:"workdir"`  // Arguments selecting the working directory
+	Continue []string   `yaml:"continue"` // Arguments continuing a session; resuming is unsupported when empty
+	Output   ExecOutput `yaml:"output"`
+}
+
+// ExecOutput describes the CLI's stdout. Field names are dotted JSON paths
+// (e.g. "usage.input_tokens") used in ndjson mode.
+type ExecOutput struct {
+	Format       string `yaml:"format"`        // text (default): each line is agent text; ndjson: one JSON event per line
+	Kind         string `yaml:"kind"`          // Event kind (default "type")
+	Text         string `yaml:"text"`          // Agent text (default "text")
+	Session      string `yaml:"session"`       // Session ID (default "session_id")
+	Error        string `yaml:"error"`         // Error message (default "error")
+	InputTokens  string `yaml:"input_tokens"`  // Default "usage.input_tokens"
+	OutputTokens string `yaml:"output_tokens"` // Default "usage.output_tokens"
+	CostUSD      string `yaml:"cost_usd"`      // Default "cost_usd"
 }
 
 // DefaultConfig returns a Config with hardcoded defaults.
@@ -76,6 +270,10 @@ func DefaultConfig() *Config {
 				Rotations: 3,
 				Strokes:   3,
 			},
+			Commit: Commit{
+				Trailers: []string{"run", "backend", "model", "rotation", "stroke", "tokens", "cost"},
+				Notes:    true,
+			},
 		},
 		Backends: map[string]Backend{
 			"opencode": {
@@ -112,26 +310,16 @@ func ResolveConfigPath() string {
 	return filepath.Join(home, ".config", "turbine", "turbine.yaml")
 }
 
-// Load loads the configuration from the global config path.
-// If the file is missing, it returns the default configuration without error.
+// Load loads the configuration used outside a repository: the global config
+// and TURBINE_* variables over the defaults, as LoadLayered without a
+// repository. Unlike LoadLayered, it returns no config when validation fails.
 func Load() (*Config, error) {
-	path := ResolveConfigPath()
-	if path == "" {
-		return DefaultConfig(), nil
-	}
-
-	data, err := os.ReadFile(path)
+	l, err := LoadLayered("", LoadOptions{})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return DefaultConfig(), nil
-		}
 		return nil, err
 	}
-
-	cfg := DefaultConfig()
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, err
-	}
-
-	return cfg, nil
+	return l.Config, nil
 }
+
+// ProjectRelPath is the project config file, relative to the repository root.
+const ProjectRelPath = ".turbine/config.yaml"
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,6 +39,9 @@ func TestResolveConfigPath(t *testing.T) {
 }
 
 func TestLoad(t *testing.T) {
+	for _, env := range EnvVars {
+		t.Setenv(env.Name, "")
+	}
 	t.Run("missing file returns defaults", func(t *testing.T) {
 		oldXDG := os.Getenv("XDG_CONFIG_HOME")
 		defer func() { _ = os.Setenv("XDG_CONFIG_HOME", oldXDG) }()
@@ -70,6 +74,9 @@ defaults:
   retry:
     rotations: 5
     strokes: 10
+  commit:
+    trailers: ["run", "cost"]
+    notes: false
 backends:
   opencode:
     command: "custom-opencode"
@@ -91,6 +98,8 @@ backends:
 		assert.True(t, cfg.Defaults.Quiet)
 		assert.Equal(t, 5, cfg.Defaults.Retry.Rotations)
 		assert.Equal(t, 10, cfg.Defaults.Retry.Strokes)
+		assert.Equal(t, []string{"run", "cost"}, cfg.Defaults.Commit.Trailers)
+		assert.False(t, cfg.Defaults.Commit.Notes)
 
 		assert.Equal(t, "custom-opencode", cfg.Backends["opencode"].Command)
 		assert.Equal(t, []string{"--debug"}, cfg.Backends["opencode"].Args)
@@ -122,3 +131,16 @@ backends:
 		assert.Nil(t, cfg)
 	})
 }
+
+func TestBackoff(t *testing.T) {
+	var b Backoff
+	assert.Equal(t, DefaultBackoffAttempts, b.MaxAttempts())
+	assert.Equal(t, 2*time.Second, b.Delay(1))
+	assert.Equal(t, 8*time.Second, b.Delay(3))
+	assert.Equal(t, time.Minute, b.Delay(10))
+
+	b = Backoff{Attempts: -1, Initial: time.Second, Max: 3 * time.Second}
+	assert.Equal(t, 0, b.MaxAttempts())
+	assert.Equal(t, 2*time.Second, b.Delay(2))
+	assert.Equal(t, 3*time.Second, b.Delay(3))
+}
@@ -0,0 +1,506 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layer is where a config value came from. Later layers override earlier ones:
+// default, global, project, profile, env, flag.
+type Layer string
+
+const (
+	LayerDefault Layer = "default"
+	LayerGlobal  Layer = "global"
+	LayerProject Layer = "project"
+	LayerProfile Layer = "profile"
+	LayerEnv     Layer = "env"
+	LayerFlag    Layer = "flag"
+	// LayerRun selects the profile recorded by the run being resumed.
+	LayerRun Layer = "run"
+)
+
+// Source is the layer a value came from and where in it: a file, an
+// environment variable or a flag.
+type Source struct {
+	Layer  Layer
+	Origin string
+	Line   int // Line in the file, for the global and project layers
+}
+
+func (s Source) String() string {
+	if s.Origin == "" {
+		return string(s.Layer)
+	}
+	return fmt.Sprintf("%s (%s)", s.Layer, s.Origin)
+}
+
+// Layered is a config merged from every layer, remembering where each value
+// came from.
+type Layered struct {
+	*Config
+	// Model and Variant select the model like --model and --variant when set
+	// by an environment variable or flag.
+	Model   string
+	Variant string
+	// Profile is the name of the applied profile, if any.
+	Profile string
+
+	// node is the merged config files, which profiles are applied to.
+	node *yaml.Node
+	// sources maps dotted keys (defaults.retry.rotations, defaults.failover[1])
+	// to the layer that set them. Keys not present come from an ancestor key or
+	// the defaults.
+	sources map[string]Source
+}
+
+// EnvVar is an environment variable that overrides a config key.
+type EnvVar struct {
+	Name string
+	Key  string
+	set  func(l *Layered, value string) error
+}
+
+// EnvVars lists the environment variables applied over the config files. The
+// model and variant keys select the model like --model and --variant.
+var EnvVars = []EnvVar{
+	{Name: "TURBINE_PROFILE", Key: "defaults.profile"}, // Applied with the config files
+	{Name: "TURBINE_BACKEND", Key: "defaults.backend", set: func(l *Layered, v string) error { l.Defaults.Backend = v; return nil }},
+	{Name: "TURBINE_MODEL", Key: "model", set: func(l *Layered, v string) error { l.Model = v; return nil }},
+	{Name: "TURBINE_VARIANT", Key: "variant", set: func(l *Layered, v string) error { l.Variant = v; return nil }},
+	{Name: "TURBINE_QUIET", Key: "defaults.quiet", set: func(l *Layered, v string) error { return parseBool(v, &l.Defaults.Quiet) }},
+	{Name: "TURBINE_ROTATIONS", Key: "defaults.retry.rotations", set: func(l *Layered, v string) error { return parseInt(v, &l.Defaults.Retry.Rotations) }},
+	{Name: "TURBINE_STROKES", Key: "defaults.retry.strokes", set: func(l *Layered, v string) error { return parseInt(v, &l.Defaults.Retry.Strokes) }},
+	{Name: "TURBINE_MAX_TOKENS", Key: "defaults.budget.max_tokens", set: func(l *Layered, v string) error { return parseInt(v, &l.Defaults.Budget.MaxTokens) }},
+	{Name: "TURBINE_MAX_COST_USD", Key: "defaults.budget.max_cost_usd", set: func(l *Layered, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		l.Defaults.Budget.MaxCostUSD = f
+		return nil
+	}},
+}
+
+func parseInt(v string, dst *int) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func parseBool(v string, dst *bool) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", v)
+	}
+	*dst = b
+	return nil
+}
+
+// configFile is a config file and the layer it belongs to.
+type configFile struct {
+	path   string
+	layer  Layer
+	origin string
+}
+
+// LoadOptions selects what LoadLayered applies besides the config files and
+// environment variables.
+type LoadOptions struct {
+	// Profile selects a profile over TURBINE_PROFILE and defaults.profile.
+	Profile string
+	// ProfileSource is where Profile was chosen; the --profile flag when unset.
+	ProfileSource Source
+	// ProfileSet makes Profile final even when empty, so TURBINE_PROFILE and
+	// defaults.profile are not consulted. A resumed run started without a
+	// profile sets it.
+	ProfileSet bool
+}
+
+// LoadLayered loads the config for the repository at repoRoot: the built-in
+// defaults, the global config, the project config at .turbine/config.yaml
+// (skipped when repoRoot is empty), the selected profile, then the TURBINE_*
+// environment variables. Other flags are applied with ApplyFlags.
+//
+// Unknown keys and values of the wrong type are errors naming the file and line.
+// When the files load but the merged config fails Validate, LoadLayered returns
+// the config together with the *ValidationError.
+//
+// Project values are merged key by key over global ones. Lists replace lists,
+// except hooks: project hooks run after global hooks at each point.
+func LoadLayered(repoRoot string, opts LoadOptions) (*Layered, error) {
+	var files []configFile
+	if path := ResolveConfigPath(); path != "" {
+		files = append(files, configFile{path: path, layer: LayerGlobal, origin: path})
+	}
+	if repoRoot != "" {
+		files = append(files, configFile{path: filepath.Join(repoRoot, ProjectRelPath), layer: LayerProject, origin: ProjectRelPath})
+	}
+
+	l, err := loadFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	name, source := opts.Profile, opts.ProfileSource
+	if name != "" && source.Layer == "" {
+		source = Source{Layer: LayerFlag, Origin: "--profile"}
+	}
+	if value := os.Getenv("TURBINE_PROFILE"); name == "" && !opts.ProfileSet && value != "" {
+		name, source = value, Source{Layer: LayerEnv, Origin: "TURBINE_PROFILE"}
+	}
+	if name == "" && !opts.ProfileSet {
+		name, source = l.Defaults.Profile, l.Source("defaults.profile")
+	}
+	if name != "" {
+		if err := l.applyProfile(name, source); err != nil {
+			return nil, err
+		}
+	} else if l.Defaults.Profile != "" {
+		l.Defaults.Profile = ""
+		l.setSource("defaults.profile", source)
+	}
+
+	for _, env := range EnvVars {
+		value, ok := os.LookupEnv(env.Name)
+		if !ok || value == "" || env.set == nil {
+			continue
+		}
+		if err := env.set(l, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", env.Name, err)
+		}
+		l.setSource(env.Key, Source{Layer: LayerEnv, Origin: env.Name})
+	}
+	return l, l.Validate()
+}
+
+// loadFiles merges the config files that exist over the defaults.
+func loadFiles(files []configFile) (*Layered, error) {
+	l := &Layered{Config: DefaultConfig(), sources: map[string]Source{}}
+
+	var (
+		merged   *yaml.Node
+		problems []Problem
+	)
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read %s: %w", f.origin, err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", f.origin, err)
+		}
+		if len(doc.Content) == 0 || isNull(doc.Content[0]) {
+			continue
+		}
+		root := doc.Content[0]
+		// Check each file on its own so problems name the file they are in.
+		source := Source{Layer: f.layer, Origin: f.origin}
+		if fileProblems := append(checkKeys(root, reflect.TypeOf(Config{}), "", source), decodeProblems(root, source)...); len(fileProblems) > 0 {
+			problems = append(problems, fileProblems...)
+			continue
+		}
+
+		if merged == nil {
+			if merged, err = defaultNode(); err != nil {
+				return nil, err
+			}
+		}
+		l.merge(merged, root, "", source)
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	if merged == nil {
+		return l, nil
+	}
+
+	l.node = merged
+	return l, l.decode()
+}
+
+// decode sets Config from the merged config files.
+func (l *Layered) decode() error {
+	var cfg Config
+	if err := l.node.Decode(&cfg); err != nil {
+		return fmt.Errorf("merge config: %w", err)
+	}
+	l.Config = &cfg
+	return nil
+}
+
+// applyProfile merges the named profile over the config files. source is where
+// the profile was selected.
+func (l *Layered) applyProfile(name string, source Source) error {
+	var profile *yaml.Node
+	if l.node != nil {
+		if profiles := mappingValue(l.node, "profiles"); profiles != nil {
+			profile = mappingValue(profiles, name)
+		}
+	}
+	if profile == nil || profile.Kind != yaml.MappingNode {
+		msg := fmt.Sprintf("profile %q is not configured", name)
+		if names := slices.Sorted(maps.Keys(l.Profiles)); len(names) > 0 {
+			msg += " (available: " + strings.Join(names, ", ") + ")"
+		}
+		return &ValidationError{Problems: []Problem{{Source: source, Key: "defaults.profile", Message: msg}}}
+	}
+
+	// Profile values keep the file and line they are set on, so problems in
+	// them point at the profile.
+	at := func(key string) Source {
+		return Source{Layer: LayerProfile, Origin: l.Source("profiles." + name + "." + key).Origin}
+	}
+	defaults := mappingValue(l.node, "defaults")
+	if value := mappingValue(profile, "defaults"); value != nil {
+		l.merge(defaults, value, "defaults", at("defaults"))
+	}
+	for _, key := range []string{"backend", "retry"} {
+		if value := mappingValue(profile, key); value != nil {
+			l.merge(mappingValue(defaults, key), value, "defaults."+key, at(key))
+		}
+	}
+	if models := mappingValue(profile, "models"); models != nil {
+		backend := mappingValue(defaults, "backend").Value
+		b := mappingValue(mappingValue(l.node, "backends"), backend)
+		if b == nil {
+			return &ValidationError{Problems: []Problem{{Source: l.Source("defaults.backend"), Key: "defaults.backend", Message: fmt.Sprintf("backend %q is not configured", backend)}}}
+		}
+		if mappingValue(b, "models") == nil {
+			b.Content = append(b.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "models"}, &yaml.Node{Kind: yaml.MappingNode})
+		}
+		l.merge(mappingValue(b, "models"), models, "backends."+backend+".models", at("models"))
+	}
+	if err := l.decode(); err != nil {
+		return err
+	}
+
+	l.Profile = name
+	l.Defaults.Profile = name
+	if source.Layer != LayerGlobal && source.Layer != LayerProject {
+		l.setSource("defaults.profile", source)
+	}
+	return nil
+}
+
+// defaultNode returns the built-in defaults as a YAML tree, so files merge over
+// every default key, including those of the built-in backends.
+func defaultNode() (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(DefaultConfig()); err != nil {
+		return nil, fmt.Errorf("encode defaults: %w", err)
+	}
+	return &node, nil
+}
+
+// merge merges src over dst, recording src as the source of every key it sets.
+func (l *Layered) merge(dst, src *yaml.Node, key string, source Source) {
+	switch {
+	case isNull(src):
+		return
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			name, value := src.Content[i].Value, src.Content[i+1]
+			child := joinKey(key, name)
+			at := source
+			at.Line = src.Content[i].Line
+			if existing := mappingValue(dst, name); existing != nil {
+				l.merge(existing, value, child, at)
+				continue
+			}
+			if isNull(value) {
+				continue
+			}
+			dst.Content = append(dst.Content, src.Content[i], value)
+			l.setSource(child, at)
+		}
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && strings.HasPrefix(key, "defaults.hooks.") && source.Layer == LayerProject:
+		for _, item := range src.Content {
+			at := source
+			at.Line = item.Line
+			l.sources[fmt.Sprintf("%s[%d]", key, len(dst.Content))] = at
+			dst.Content = append(dst.Content, item)
+		}
+	default:
+		*dst = *src
+		l.setSource(key, source)
+		if src.Kind == yaml.SequenceNode {
+			// Items keep their own lines for error messages.
+			for i, item := range src.Content {
+				at := source
+				at.Line = item.Line
+				l.sources[fmt.Sprintf("%s[%d]", key, i)] = at
+			}
+		}
+	}
+}
+
+// setSource records source for key, replacing what was recorded for its children.
+func (l *Layered) setSource(key string, source Source) {
+	for k := range l.sources {
+		if strings.HasPrefix(k, key+".") || strings.HasPrefix(k, key+"[") {
+			delete(l.sources, k)
+		}
+	}
+	l.sources[key] = source
+}
+
+// Source returns where the value of a dotted key came from.
+func (l *Layered) Source(key string) Source {
+	for {
+		if s, ok := l.sources[key]; ok {
+			return s
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			return Source{Layer: LayerDefault}
+		}
+		key = key[:i]
+	}
+}
+
+// ApplyFlags applies the flags that override the config.
+func (l *Layered) ApplyFlags(o Overrides) {
+	if o.Backend != "" {
+		l.Defaults.Backend = o.Backend
+		l.setSource("defaults.backend", Source{Layer: LayerFlag, Origin: "--backend"})
+	}
+	if o.Model != "" {
+		l.Model = o.Model
+		l.setSource("model", Source{Layer: LayerFlag, Origin: "--model"})
+	}
+	if o.Variant != "" {
+		l.Variant = o.Variant
+		l.setSource("variant", Source{Layer: LayerFlag, Origin: "--variant"})
+	}
+	if o.Quiet {
+		l.Defaults.Quiet = true
+		l.setSource("defaults.quiet", Source{Layer: LayerFlag, Origin: "--quiet"})
+	}
+}
+
+// Overrides returns the model selection made by environment variables and flags.
+// The backend is already applied to Defaults.Backend.
+func (l *Layered) Overrides() Overrides {
+	return Overrides{Model: l.Model, Variant: l.Variant}
+}
+
+// Annotated renders the effective config as YAML with the source of every value
+// that does not come from the defaults as a line comment. Empty defaults are
+// omitted.
+func (l *Layered) Annotated() ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(l.Config); err != nil {
+		return nil, fmt.Errorf("encode config: %w", err)
+	}
+	if l.node != nil {
+		// Profiles are shown as written rather than with every key they can set.
+		if profiles := mappingValue(l.node, "profiles"); profiles != nil {
+			copied := *profiles
+			copied.Style &^= yaml.FlowStyle // Encoded empty in the defaults
+			*mappingValue(&doc, "profiles") = copied
+		}
+	}
+	l.annotate(&doc, "", Source{Layer: LayerDefault})
+
+	var buf bytes.Buffer
+	for _, key := range []string{"model", "variant"} {
+		if s, ok := l.sources[key]; ok {
+			value := l.Model
+			if key == "variant" {
+				value = l.Variant
+			}
+			fmt.Fprintf(&buf, "# %s: %s  # %s\n", key, value, s)
+		}
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// annotate comments the values of node whose source differs from their
+// parent's with that source, and removes empty defaults. It reports whether
+// node should be kept.
+func (l *Layered) annotate(node *yaml.Node, key string, parent Source) bool {
+	source := l.Source(key)
+	comment := ""
+	if source.Layer != LayerDefault && (source.Layer != parent.Layer || source.Origin != parent.Origin) {
+		comment = source.String()
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			child := joinKey(key, k.Value)
+			if !l.annotate(v, child, source) {
+				continue
+			}
+			if v.Kind != yaml.ScalarNode && len(v.Content) > 0 {
+				// Comment the key of a block so the source is on the line that names it.
+				k.LineComment, v.LineComment = v.LineComment, ""
+			}
+			content = append(content, k, v)
+		}
+		node.Content = content
+		node.LineComment = comment
+		return len(content) > 0 || source.Layer != LayerDefault
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			l.annotate(item, fmt.Sprintf("%s[%d]", key, i), source)
+		}
+		node.LineComment = comment
+		return len(node.Content) > 0 || source.Layer != LayerDefault
+	case yaml.ScalarNode:
+		if source.Layer == LayerDefault {
+			switch node.Value {
+			case "", "0", "false", "0s":
+				return false
+			}
+		}
+		node.LineComment = comment
+	}
+	return true
+}
+
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func mappingValue(node *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
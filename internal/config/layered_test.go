@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLayered(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	for _, env := range EnvVars {
+		t.Setenv(env.Name, "")
+	}
+	global := filepath.Join(configHome, "turbine", "turbine.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(global), 0755))
+	require.NoError(t, os.WriteFile(global, []byte(`
+defaults:
+  backend: claude
+  retry:
+    rotations: 5
+  failover: [opencode]
+  hooks:
+    pre_commit: ["go generate ./..."]
+backends:
+  claude:
+    models:
+      fast: sonnet
+`), 0644))
+
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ProjectRelPath), []byte(`
+defaults:
+  retry:
+    strokes: 2
+  failover: []
+  hooks:
+    pre_commit: ["gofmt -l ."]
+backends:
+  claude:
+    models:
+      slow: opus
+`), 0644))
+	t.Setenv("TURBINE_ROTATIONS", "7")
+	t.Setenv("TURBINE_MODEL", "slow")
+
+	l, err := LoadLayered(repo, LoadOptions{})
+	require.NoError(t, err)
+	l.ApplyFlags(Overrides{Backend: "opencode"})
+
+	assert.Equal(t, "opencode", l.Defaults.Backend)
+	assert.Equal(t, 7, l.Defaults.Retry.Rotations)
+	assert.Equal(t, 2, l.Defaults.Retry.Strokes)
+	assert.Empty(t, l.Defaults.Failover)
+	assert.Equal(t, []string{"go generate ./...", "gofmt -l ."}, l.Defaults.Hooks.PreCommit, "project hooks run after global hooks")
+	assert.Equal(t, "claude", l.Backends["claude"].Command, "built-in backend keys are kept")
+	assert.Equal(t, "sonnet", l.Backends["claude"].Models.Fast.Name)
+	assert.Equal(t, "opus", l.Backends["claude"].Models.Slow.Name)
+	assert.Equal(t, Overrides{Model: "slow"}, l.Overrides())
+
+	tests := map[string]Source{
+		"defaults.backend":                 {Layer: LayerFlag, Origin: "--backend"},
+		"defaults.retry.rotations":         {Layer: LayerEnv, Origin: "TURBINE_ROTATIONS"},
+		"defaults.retry.strokes":           {Layer: LayerProject, Origin: ProjectRelPath},
+		"defaults.failover":                {Layer: LayerProject, Origin: ProjectRelPath},
+		"defaults.hooks.pre_commit[0]":     {Layer: LayerGlobal, Origin: global},
+		"defaults.hooks.pre_commit[1]":     {Layer: LayerProject, Origin: ProjectRelPath},
+		"backends.claude.models.fast":      {Layer: LayerGlobal, Origin: global},
+		"backends.claude.models.slow":      {Layer: LayerProject, Origin: ProjectRelPath},
+		"backends.claude.command":          {Layer: LayerDefault},
+		"defaults.commit.notes":            {Layer: LayerDefault},
+		"model":                            {Layer: LayerEnv, Origin: "TURBINE_MODEL"},
+		"defaults.retry.transient.initial": {Layer: LayerDefault},
+	}
+	for key, want := range tests {
+		got := l.Source(key)
+		assert.Equal(t, want, Source{Layer: got.Layer, Origin: got.Origin}, key)
+	}
+	assert.Equal(t, 4, l.Source("defaults.retry.strokes").Line)
+	assert.Equal(t, 7, l.Source("defaults.hooks.pre_commit[1]").Line)
+
+	out, err := l.Annotated()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "# model: slow  # env (TURBINE_MODEL)\n")
+	assert.Contains(t, string(out), "backend: opencode # flag (--backend)\n")
+	assert.Contains(t, string(out), "strokes: 2 # project (.turbine/config.yaml)\n")
+	assert.Contains(t, string(out), "failover: [] # project (.turbine/config.yaml)\n")
+	assert.Contains(t, string(out), "- gofmt -l . # project (.turbine/config.yaml)\n")
+	assert.NotContains(t, string(out), "max_tokens", "empty defaults are omitted")
+
+	t.Setenv("TURBINE_STROKES", "many")
+	_, err = LoadLayered(repo, LoadOptions{})
+	assert.EqualError(t, err, `TURBINE_STROKES: invalid integer "many"`)
+
+	t.Setenv("TURBINE_STROKES", "")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ProjectRelPath), []byte("defaults:\n  retry:\n    strokes: many\n"), 0644))
+	_, err = LoadLayered(repo, LoadOptions{})
+	assert.ErrorContains(t, err, ProjectRelPath+":3: cannot unmarshal !!str `many` into int")
+}
+
+func TestLoadLayered_Profiles(t *testing.T) {
+	for _, env := range EnvVars {
+		t.Setenv(env.Name, "")
+	}
+	global := writeGlobal(t, `defaults:
+  backend: claude
+  profile: cheap
+  retry:
+    rotations: 5
+profiles:
+  cheap:
+    models:
+      slow: haiku
+    retry:
+      strokes: 1
+  thorough:
+    backend: opencode
+    models:
+      fast: big
+    defaults:
+      budget:
+        max_cost_usd: 20
+`)
+
+	l, err := LoadLayered("", LoadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "cheap", l.Profile)
+	assert.Equal(t, "claude", l.Defaults.Backend)
+	assert.Equal(t, "haiku", l.Backends["claude"].Models.Slow.Name)
+	assert.Equal(t, 5, l.Defaults.Retry.Rotations, "values the profile does not set are kept")
+	assert.Equal(t, 1, l.Defaults.Retry.Strokes)
+	strokes := l.Source("defaults.retry.strokes")
+	assert.Equal(t, Source{Layer: LayerProfile, Origin: global, Line: 11}, strokes)
+	out, err := l.Annotated()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "    strokes: 1 # profile ("+global+")\n")
+	assert.Contains(t, string(out), "profiles:\n  cheap: # global ("+global+")\n    models:\n      slow: haiku\n", "profiles are shown as written")
+
+	t.Setenv("TURBINE_PROFILE", "thorough")
+	l, err = LoadLayered("", LoadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "thorough", l.Profile)
+	assert.Equal(t, "opencode", l.Defaults.Backend)
+	assert.Equal(t, "big", l.Backends["opencode"].Models.Fast.Name)
+	assert.Equal(t, 20.0, l.Defaults.Budget.MaxCostUSD)
+	assert.Equal(t, 3, l.Defaults.Retry.Strokes, "the other profile is not applied")
+	assert.Equal(t, Source{Layer: LayerEnv, Origin: "TURBINE_PROFILE"}, l.Source("defaults.profile"))
+
+	run := Source{Layer: LayerRun, Origin: "run-1"}
+	l, err = LoadLayered("", LoadOptions{Profile: "cheap", ProfileSource: run})
+	require.NoError(t, err)
+	assert.Equal(t, "cheap", l.Profile)
+	assert.Equal(t, run, l.Source("defaults.profile"))
+
+	_, err = LoadLayered("", LoadOptions{Profile: "fast"})
+	assert.EqualError(t, err, `invalid config: --profile: defaults.profile: profile "fast" is not configured (available: cheap, thorough)`)
+}
@@ -5,6 +5,7 @@ type Overrides struct {
 	Model   string // "fast", "slow", or custom model name
 	Variant string
 	Yes     bool
+	Quiet   bool
 	Verbose bool
 	Debug   bool
 }
@@ -65,6 +66,9 @@ func Merge(cfg *Config, overrides Overrides) *EffectiveConfig {
 	if overrides.Yes {
 		eff.Yes = true
 	}
+	if overrides.Quiet {
+		eff.Quiet = true
+	}
 	if overrides.Verbose {
 		eff.Verbose = true
 		eff.Quiet = false
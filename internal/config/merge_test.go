@@ -49,6 +49,15 @@ func TestMerge(t *testing.T) {
 		assert.False(t, eff.Quiet)
 	})
 
+	t.Run("quiet override", func(t *testing.T) {
+		eff := Merge(DefaultConfig(), Overrides{Quiet: true})
+		assert.True(t, eff.Quiet)
+
+		eff = Merge(DefaultConfig(), Overrides{Quiet: true, Verbose: true})
+		assert.False(t, eff.Quiet)
+		assert.True(t, eff.Verbose)
+	})
+
 	t.Run("unknown backend", func(t *testing.T) {
 		cfg := DefaultConfig()
 		eff := Merge(cfg, Overrides{Backend: "ghost"})
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Schema returns a JSON Schema of the config file, generated from the Config
+// type, for editor completion and validation. Unknown keys are rejected as they
+// are by Load.
+func Schema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Turbine configuration"
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// profileKeys maps the keys of a profile to the key patterns they override.
+var profileKeys = map[string]string{
+	"backend":  "defaults.backend",
+	"models":   "backends.*.models",
+	"retry":    "defaults.retry",
+	"defaults": "defaults",
+}
+
+// schemaFor returns the schema of type t at key pattern (see enums).
+func schemaFor(t reflect.Type, pattern string) map[string]any {
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "string", "pattern": `^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`}
+	case t == reflect.TypeOf(Model{}):
+		return map[string]any{"anyOf": []any{
+			map[string]any{"type": "string"},
+			schemaFor(reflect.TypeOf(struct {
+				Name    string `yaml:"name"`
+				Variant string `yaml:"variant"`
+			}{}), pattern),
+		}}
+	}
+
+	var s map[string]any
+	switch t.Kind() {
+	case reflect.Struct:
+		props := map[string]any{}
+		for name, f := range yamlFields(t) {
+			child := joinKey(pattern, name)
+			if t == reflect.TypeOf(Profile{}) {
+				// Profile values are checked like the values they override.
+				child = profileKeys[name]
+			}
+			props[name] = schemaFor(f.Type, child)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), pattern+".*")}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), pattern+"[]")}
+	case reflect.String:
+		s = map[string]any{"type": "string"}
+		if values, ok := enums[pattern]; ok {
+			s["enum"] = values
+		}
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		s = map[string]any{"type": "integer"}
+	case reflect.Float64:
+		s = map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+	if m, ok := minimums[pattern]; ok {
+		s["minimum"] = m
+	}
+	return s
+}
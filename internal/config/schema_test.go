@@ -0,0 +1,20 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema()
+	require.NoError(t, err)
+	assert.True(t, json.Valid(schema))
+
+	published, err := os.ReadFile("../../docs/turbine.schema.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(published), string(schema), "regenerate with: go run . config schema > docs/turbine.schema.json")
+}
@@ -0,0 +1,41 @@
+package config
+
+// ProjectTemplate is the project config written by turbine init. Every setting
+// is commented out, so it changes nothing until edited. Settings are the YAML
+// line right after the "#"; prose comments start with "# " and a word.
+const ProjectTemplate = `# yaml-language-server: $schema=https://raw.githubusercontent.com/yarlson/turbine/main/docs/turbine.schema.json
+#
+# Turbine project config, merged over the global config. Commit it to share
+# settings; set only the keys that differ. Run "turbine config show --effective"
+# to see the merged config and "turbine config validate" to check it.
+# See docs/CONFIGURATION.md for every setting.
+
+#defaults:
+#  # Backend used for planning and strokes (opencode, claude or one defined below).
+#  backend: claude
+#
+#  # Rotations per task and strokes per rotation before a task fails.
+#  retry:
+#    rotations: 3
+#    strokes: 3
+#
+#  # Stop the run once its backend usage reaches a limit.
+#  budget:
+#    max_cost_usd: 10
+#
+#  # Shell commands run at fixed points of a run, after the global hooks.
+#  hooks:
+#    pre_commit: ["go test ./..."]
+
+#backends:
+#  claude:
+#    models:
+#      fast: claude-sonnet-4-5
+#      slow: claude-opus-4-1
+
+# Named overrides selected with --profile or defaults.profile.
+#profiles:
+#  cheap:
+#    retry:
+#      rotations: 1
+`
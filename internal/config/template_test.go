@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectTemplate(t *testing.T) {
+	writeGlobal(t, "")
+	for _, env := range EnvVars {
+		t.Setenv(env.Name, "")
+	}
+	repo := t.TempDir()
+	path := filepath.Join(repo, ProjectRelPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+
+	require.NoError(t, os.WriteFile(path, []byte(ProjectTemplate), 0644))
+	l, err := LoadLayered(repo, LoadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig().Defaults.Backend, l.Defaults.Backend, "the template changes nothing")
+
+	// The commented-out settings stay valid as the config changes.
+	lines := strings.Split(ProjectTemplate, "\n")
+	for i, line := range lines {
+		prose := strings.HasPrefix(line, "# ") && !strings.HasPrefix(line, "#  ")
+		if strings.HasPrefix(line, "#") && !prose {
+			lines[i] = line[1:]
+		}
+	}
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644))
+	l, err = LoadLayered(repo, LoadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "claude", l.Defaults.Backend)
+	assert.Contains(t, l.Profiles, "cheap")
+}
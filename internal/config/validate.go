@@ -0,0 +1,318 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an invalid or unknown config value.
+type Problem struct {
+	Source  Source // Where the value is set
+	Key     string // Dotted key, e.g. defaults.retry.strokes
+	Message string
+}
+
+func (p Problem) Error() string {
+	var loc string
+	switch p.Source.Layer {
+	case LayerGlobal, LayerProject, LayerProfile:
+		loc = p.Source.Origin
+		if p.Source.Line > 0 {
+			loc += ":" + strconv.Itoa(p.Source.Line)
+		}
+	case LayerDefault:
+		loc = "built-in default"
+	default:
+		loc = p.Source.Origin
+	}
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s", loc, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, p.Key, p.Message)
+}
+
+// ValidationError lists every problem found in the config.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid config: " + e.Problems[0].Error()
+	}
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid config (%d problems):", len(e.Problems)))
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Allowed values of string settings, by key pattern: "*" matches any map key
+// and "[]" any list item. Shared by Validate and Schema.
+var enums = map[string][]string{
+	"backends.*.type":               {"opencode", "claude", "exec", "fake"},
+	"backends.*.exec.output.format": {"text", "ndjson"},
+	"defaults.commit.sign":          {"gpg", "ssh", "off"},
+	"defaults.commit.trailers[]":    {"run", "backend", "model", "rotation", "stroke", "tokens", "cost"},
+	"defaults.notify[].events[]":    {"task_done", "task_failed", "run_finished", "budget_exceeded"},
+}
+
+// Minimum values of numeric settings, by key pattern as in enums.
+var minimums = map[string]int{
+	"defaults.retry.rotations":            1,
+	"defaults.retry.strokes":              1,
+	"defaults.retention.keep_runs":        0,
+	"defaults.retention.max_age_days":     0,
+	"defaults.retention.max_size_mb":      0,
+	"defaults.budget.max_cost_usd":        0,
+	"defaults.budget.max_tokens":          0,
+	"defaults.commit.message.max_subject": 0,
+	"defaults.notify[].timeout_seconds":   0,
+}
+
+// checkKeys reports the keys of node that t does not have, with the line they
+// are on.
+func checkKeys(node *yaml.Node, t reflect.Type, key string, source Source) []Problem {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch {
+	case t == reflect.TypeOf(Model{}) && node.Kind == yaml.ScalarNode:
+		return nil
+	case t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Duration(0)):
+		if node.Kind != yaml.MappingNode {
+			return nil // Decode reports the type mismatch.
+		}
+		fields := yamlFields(t)
+		var problems []Problem
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			child := joinKey(key, k.Value)
+			at := source
+			at.Line = k.Line
+			field, ok := fields[k.Value]
+			if !ok {
+				msg := "unknown key"
+				if s := suggest(k.Value, fields); s != "" {
+					msg += fmt.Sprintf(" (did you mean %s?)", s)
+				}
+				problems = append(problems, Problem{Source: at, Key: child, Message: msg})
+				continue
+			}
+			problems = append(problems, checkKeys(v, field.Type, child, at)...)
+		}
+		return problems
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		var problems []Problem
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			at := source
+			at.Line = node.Content[i].Line
+			problems = append(problems, checkKeys(node.Content[i+1], t.Elem(), joinKey(key, node.Content[i].Value), at)...)
+		}
+		return problems
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		var problems []Problem
+		for i, item := range node.Content {
+			at := source
+			at.Line = item.Line
+			problems = append(problems, checkKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", key, i), at)...)
+		}
+		return problems
+	}
+	return nil
+}
+
+// yamlFields maps the YAML keys of struct type t to its fields.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// suggest returns the field name closest to a misspelled key, if any is close.
+func suggest(key string, fields map[string]reflect.StructField) string {
+	best, bestDist := "", 3
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+var typeErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// decodeProblems decodes a config file's root node and converts type errors to
+// problems.
+func decodeProblems(root *yaml.Node, source Source) []Problem {
+	err := root.Decode(DefaultConfig())
+	if err == nil {
+		return nil
+	}
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		return []Problem{{Source: source, Message: err.Error()}}
+	}
+	problems := make([]Problem, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		at := source
+		if m := typeErrorLine.FindStringSubmatch(msg); m != nil {
+			at.Line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		problems = append(problems, Problem{Source: at, Message: msg})
+	}
+	return problems
+}
+
+// Validate checks the merged config for values that parse but cannot work, such
+// as zero retry counts or references to undefined backends. Each problem names
+// the layer the value came from.
+func (l *Layered) Validate() error {
+	var problems []Problem
+	add := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Source: l.Source(key), Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+	enum := func(key, pattern, value string) {
+		if value != "" && !slices.Contains(enums[pattern], value) {
+			add(key, "%q is not one of %s", value, strings.Join(enums[pattern], ", "))
+		}
+	}
+	atLeast := func(key, pattern string, value float64) {
+		if m := minimums[pattern]; value < float64(m) {
+			add(key, "must be at least %d", m)
+		}
+	}
+	minimum := func(key string, value float64) { atLeast(key, key, value) }
+	backend := func(key, name string) {
+		if _, ok := l.Backends[name]; !ok {
+			add(key, "backend %q is not configured", name)
+		}
+	}
+
+	d := l.Defaults
+	backend("defaults.backend", d.Backend)
+	minimum("defaults.retry.rotations", float64(d.Retry.Rotations))
+	minimum("defaults.retry.strokes", float64(d.Retry.Strokes))
+	if d.Retry.Transient.Initial < 0 || d.Retry.Transient.Max < 0 {
+		add("defaults.retry.transient", "delays must not be negative")
+	}
+	minimum("defaults.retention.keep_runs", float64(d.Retention.KeepRuns))
+	minimum("defaults.retention.max_age_days", float64(d.Retention.MaxAgeDays))
+	minimum("defaults.retention.max_size_mb", float64(d.Retention.MaxSizeMB))
+	minimum("defaults.budget.max_cost_usd", d.Budget.MaxCostUSD)
+	minimum("defaults.budget.max_tokens", float64(d.Budget.MaxTokens))
+	enum("defaults.commit.sign", "defaults.commit.sign", d.Commit.Sign)
+	if d.Commit.SigningKey != "" && (d.Commit.Sign == "" || d.Commit.Sign == "off") {
+		add("defaults.commit.signing_key", "has no effect unless sign is gpg or ssh")
+	}
+	for i, t := range d.Commit.Trailers {
+		enum(fmt.Sprintf("defaults.commit.trailers[%d]", i), "defaults.commit.trailers[]", t)
+	}
+	if !slices.Contains(d.Commit.Trailers, "run") {
+		add("defaults.commit.trailers", "must include run; turbine squash finds a run's commits by it")
+	}
+	minimum("defaults.commit.message.max_subject", float64(d.Commit.Message.MaxSubject))
+	for i, n := range d.Notify {
+		key := fmt.Sprintf("defaults.notify[%d]", i)
+		if n.Webhook == "" && n.Command == "" {
+			add(key, "set webhook, command or both")
+		}
+		for j, e := range n.Events {
+			enum(fmt.Sprintf("%s.events[%d]", key, j), "defaults.notify[].events[]", e)
+		}
+		atLeast(key+".timeout_seconds", "defaults.notify[].timeout_seconds", float64(n.TimeoutSeconds))
+	}
+	for i, rung := range d.Escalation {
+		if rung.Backend != "" {
+			backend(fmt.Sprintf("defaults.escalation[%d].backend", i), rung.Backend)
+		}
+	}
+	for i, name := range d.Failover {
+		backend(fmt.Sprintf("defaults.failover[%d]", i), name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(l.Profiles)) {
+		if p := l.Profiles[name]; p.Backend != "" {
+			backend("profiles."+name+".backend", p.Backend)
+		}
+	}
+
+	names := make([]string, 0, len(l.Backends))
+	for name := range l.Backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		b := l.Backends[name]
+		key := "backends." + name
+		typ := b.Type
+		if typ == "" {
+			typ = name
+		}
+		if !slices.Contains(enums["backends.*.type"], typ) {
+			add(key+".type", "%q is not one of %s", typ, strings.Join(enums["backends.*.type"], ", "))
+			continue
+		}
+		if typ == "fake" {
+			if b.Scenario == "" {
+				add(key+".scenario", "required for type fake")
+			}
+			continue
+		}
+		if b.Command == "" {
+			add(key+".command", "must not be empty")
+		}
+		if b.Models.Fast.Name == "" {
+			add(key+".models.fast", "model name must not be empty")
+		}
+		if b.Models.Slow.Name == "" {
+			add(key+".models.slow", "model name must not be empty")
+		}
+		enum(key+".exec.output.format", "backends.*.exec.output.format", b.Exec.Output.Format)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
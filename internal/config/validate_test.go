@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGlobal(t *testing.T, content string) string {
+	t.Helper()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	path := filepath.Join(configHome, "turbine", "turbine.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestValidate_Defaults(t *testing.T) {
+	l := &Layered{Config: DefaultConfig()}
+	assert.NoError(t, l.Validate())
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	path := writeGlobal(t, `defaults:
+  retry:
+    rotatons: 5
+backends:
+  claude:
+    modles: {}
+    exec:
+      output:
+        formt: ndjson
+`)
+
+	_, err := Load()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	assert.Equal(t, []string{
+		path + ":3: defaults.retry.rotatons: unknown key (did you mean rotations?)",
+		path + ":6: backends.claude.modles: unknown key (did you mean models?)",
+		path + ":9: backends.claude.exec.output.formt: unknown key (did you mean format?)",
+	}, problemStrings(verr))
+}
+
+func TestLoadLayered_Validate(t *testing.T) {
+	path := writeGlobal(t, `defaults:
+  backend: missing
+  retry:
+    strokes: 0
+  commit:
+    sign: pgp
+    trailers: [run, costs]
+  failover: [spare]
+  notify:
+    - events: [task_done]
+backends:
+  custom:
+    type: exec
+    command: agent
+    models:
+      fast: small
+  mystery:
+    command: mystery
+`)
+	t.Setenv("TURBINE_ROTATIONS", "0")
+
+	l, err := LoadLayered("", LoadOptions{})
+	require.NotNil(t, l, "a config that loads is returned with its problems")
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	assert.Equal(t, []string{
+		path + `:2: defaults.backend: backend "missing" is not configured`,
+		"TURBINE_ROTATIONS: defaults.retry.rotations: must be at least 1",
+		path + ":4: defaults.retry.strokes: must be at least 1",
+		path + `:6: defaults.commit.sign: "pgp" is not one of gpg, ssh, off`,
+		path + `:7: defaults.commit.trailers[1]: "costs" is not one of run, backend, model, rotation, stroke, tokens, cost`,
+		path + ":10: defaults.notify[0]: set webhook, command or both",
+		path + `:8: defaults.failover[0]: backend "spare" is not configured`,
+		path + ":12: backends.custom.models.slow: model name must not be empty",
+		path + `:17: backends.mystery.type: "mystery" is not one of opencode, claude, exec, fake`,
+	}, problemStrings(verr))
+	assert.Contains(t, err.Error(), "invalid config (9 problems):\n  ")
+}
+
+func TestLoadLayered_SigningKeyWithoutSign(t *testing.T) {
+	path := writeGlobal(t, `defaults:
+  commit:
+    signing_key: ~/.ssh/id_ed25519.pub
+`)
+
+	_, err := LoadLayered("", LoadOptions{})
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	assert.Equal(t, []string{
+		path + ":3: defaults.commit.signing_key: has no effect unless sign is gpg or ssh",
+	}, problemStrings(verr))
+
+	writeGlobal(t, `defaults:
+  commit:
+    sign: ssh
+    signing_key: ~/.ssh/id_ed25519.pub
+`)
+	_, err = LoadLayered("", LoadOptions{})
+	require.NoError(t, err)
+}
+
+func TestLoadLayered_TrailersWithoutRun(t *testing.T) {
+	path := writeGlobal(t, `defaults:
+  commit:
+    trailers: [backend, cost]
+`)
+
+	_, err := LoadLayered("", LoadOptions{})
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	assert.Equal(t, []string{
+		path + ":3: defaults.commit.trailers: must include run; turbine squash finds a run's commits by it",
+	}, problemStrings(verr))
+}
+
+func problemStrings(err *ValidationError) []string {
+	out := make([]string, 0, len(err.Problems))
+	for _, p := range err.Problems {
+		out = append(out, p.Error())
+	}
+	return out
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/gitx"
 	filestore "github.com/yarlson/turbine/internal/relay/store"
 	"github.com/yarlson/turbine/internal/relay/stream"
 	"github.com/yarlson/turbine/internal/tasks"
@@ -27,10 +28,17 @@ type PlanOptions struct {
 	SlowVariant string
 	// ArtifactsDir is the path where stdout/stderr and other run data should be captured
 	ArtifactsDir string
+	// MessagePolicy is enforced on the planned commit_message; violations go through the fix loop
+	MessagePolicy gitx.MessagePolicy
+	// OnEvent, if set, receives every backend event of planning, e.g. to count usage
+	OnEvent func(relay.Event)
 }
 
 const maxValidationRetries = 2
 
+// taskRelPath is where the planner writes the next task, relative to the repository root.
+const taskRelPath = ".turbine/task.yaml"
+
 func New(backend relay.Provider, repoRoot string) *Decomposer {
 	return &Decomposer{
 		backend:  backend,
@@ -44,23 +52,12 @@ func New(backend relay.Provider, repoRoot string) *Decomposer {
 // Phase 2 (slow model): Generate the next task using the gathered context
 // Both phases run in the same session using --continue.
 func (d *Decomposer) PlanNext(ctx context.Context, prdPath, progressPath string, opts PlanOptions) error {
-	prdContent, err := os.ReadFile(prdPath)
+	prdContent, progressContent, err := readPlanInputs(prdPath, progressPath)
 	if err != nil {
-		return fmt.Errorf("read PRD: %w", err)
-	}
-
-	progressContent := ""
-	if progressPath != "" {
-		content, err := os.ReadFile(progressPath)
-		if err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("read progress: %w", err)
-		}
-		if err == nil {
-			progressContent = string(content)
-		}
+		return err
 	}
 
-	outputPath := ".turbine/task.yaml"
+	outputPath := taskRelPath
 	taskPath := filepath.Join(d.repoRoot, outputPath)
 
 	// Phase 1: Explore - no methodologies needed
@@ -71,7 +68,7 @@ func (d *Decomposer) PlanNext(ctx context.Context, prdPath, progressPath string,
 
 	exec := relay.NewExecutor(d.backend)
 
-	if err := d.runWorkflow(ctx, exec, &relay.Workflow{
+	if err := d.runWorkflow(ctx, exec, opts.OnEvent, &relay.Workflow{
 		WorkingDir: d.repoRoot,
 		Sessions: []relay.Session{
 			{
@@ -93,10 +90,27 @@ func (d *Decomposer) PlanNext(ctx context.Context, prdPath, progressPath string,
 		return err
 	}
 
+	return d.fixTaskFile(ctx, exec, taskPath, prdContent, progressContent, opts, true)
+}
+
+// FixTask validates the existing .turbine/task.yaml against opts and, if it is
+// invalid, asks the planner to fix it as it does for a newly planned task.
+func (d *Decomposer) FixTask(ctx context.Context, prdPath, progressPath string, opts PlanOptions) error {
+	prdContent, progressContent, err := readPlanInputs(prdPath, progressPath)
+	if err != nil {
+		return err
+	}
+	taskPath := filepath.Join(d.repoRoot, taskRelPath)
+	return d.fixTaskFile(ctx, relay.NewExecutor(d.backend), taskPath, prdContent, progressContent, opts, false)
+}
+
+// fixTaskFile runs the fix prompt until the task file validates or the retries
+// run out. The first fix continues the planning session only when cont is set.
+func (d *Decomposer) fixTaskFile(ctx context.Context, exec *relay.Executor, taskPath string, prdContent []byte, progressContent string, opts PlanOptions, cont bool) error {
 	var lastErr error
 	for i := 0; i <= maxValidationRetries; i++ {
 		// Validate the file the agent wrote
-		lastErr = d.validateTaskFile(taskPath)
+		lastErr = d.validateTaskFile(taskPath, opts.MessagePolicy)
 		if lastErr == nil {
 			return nil
 		}
@@ -108,7 +122,7 @@ func (d *Decomposer) PlanNext(ctx context.Context, prdPath, progressPath string,
 		fileContent, _ := os.ReadFile(taskPath)
 		fixPrompt := buildPlanFixPrompt(string(prdContent), progressContent, string(fileContent), lastErr.Error())
 
-		if err := d.runWorkflow(ctx, exec, &relay.Workflow{
+		if err := d.runWorkflow(ctx, exec, opts.OnEvent, &relay.Workflow{
 			WorkingDir: d.repoRoot,
 			Sessions: []relay.Session{
 				{
@@ -117,7 +131,7 @@ func (d *Decomposer) PlanNext(ctx context.Context, prdPath, progressPath string,
 							Prompt:   fixPrompt,
 							Model:    opts.SlowModel,
 							Variant:  opts.SlowVariant,
-							Continue: true,
+							Continue: cont || i > 0,
 						},
 					},
 				},
@@ -130,19 +144,44 @@ func (d *Decomposer) PlanNext(ctx context.Context, prdPath, progressPath string,
 	return fmt.Errorf("plan failed after %d retries: %w", maxValidationRetries, lastErr)
 }
 
-func (d *Decomposer) runWorkflow(ctx context.Context, exec *relay.Executor, workflow *relay.Workflow) error {
+// readPlanInputs reads the PRD and, if it exists, the progress log.
+func readPlanInputs(prdPath, progressPath string) ([]byte, string, error) {
+	prdContent, err := os.ReadFile(prdPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("read PRD: %w", err)
+	}
+
+	progressContent := ""
+	if progressPath != "" {
+		content, err := os.ReadFile(progressPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("read progress: %w", err)
+		}
+		if err == nil {
+			progressContent = string(content)
+		}
+	}
+	return prdContent, progressContent, nil
+}
+
+// runWorkflow runs a workflow, passing each event to onEvent (if set) and
+// persisting it when the workflow has an ID.
+func (d *Decomposer) runWorkflow(ctx context.Context, exec *relay.Executor, onEvent func(relay.Event), workflow *relay.Workflow) error {
 	events := make(chan relay.Event, 128)
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		if workflow.ID == "" {
-			for range events {
-			}
-			return
+		var store *filestore.FileStore
+		if workflow.ID != "" {
+			store = filestore.New(d.repoRoot)
 		}
-		store := filestore.New(d.repoRoot)
 		for evt := range events {
-			stream.AppendEvent(ctx, store, workflow.ID, evt)
+			if onEvent != nil {
+				onEvent(evt)
+			}
+			if store != nil {
+				stream.AppendEvent(ctx, store, workflow.ID, evt)
+			}
 		}
 	}()
 
@@ -155,8 +194,9 @@ func (d *Decomposer) runWorkflow(ctx context.Context, exec *relay.Executor, work
 	return nil
 }
 
-// validateTaskFile checks that the task file exists and is valid.
-func (d *Decomposer) validateTaskFile(taskPath string) error {
+// validateTaskFile checks that the task file exists, is valid and that its
+// commit message satisfies the policy.
+func (d *Decomposer) validateTaskFile(taskPath string, policy gitx.MessagePolicy) error {
 	content, err := os.ReadFile(taskPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -179,5 +219,11 @@ func (d *Decomposer) validateTaskFile(taskPath string) error {
 		return fmt.Errorf("validate task: %w", err)
 	}
 
+	if taskFile.Task.Status != tasks.StatusDone {
+		if err := policy.Validate(taskFile.Task.CommitMessage); err != nil {
+			return fmt.Errorf("validate commit_message: %w", err)
+		}
+	}
+
 	return nil
 }
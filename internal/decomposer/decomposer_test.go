@@ -4,9 +4,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/gitx"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -139,6 +141,47 @@ task:
 		assert.FileExists(t, taskPath)
 	})
 
+	t.Run("commit message policy violation fixed by retry", func(t *testing.T) {
+		repoRoot, prdPath, progressPath := setupTempDir(t)
+		backend := &mockProvider{
+			writeFile: func(root string, call int) error {
+				if call == 1 {
+					return writeTaskFile(root, strings.Replace(validYAML, "'feat: t1'", "'Add t1'", 1))
+				}
+				if call >= 2 {
+					return writeTaskFile(root, validYAML)
+				}
+				return nil
+			},
+		}
+		d := New(backend, repoRoot)
+
+		policyOpts := opts
+		policyOpts.MessagePolicy = gitx.MessagePolicy{Conventional: true}
+		err := d.PlanNext(context.Background(), prdPath, progressPath, policyOpts)
+		require.NoError(t, err)
+		assert.Equal(t, 3, backend.calls) // 1 explore + 2 generate attempts
+	})
+
+	t.Run("fix existing task - commit message policy violation", func(t *testing.T) {
+		repoRoot, prdPath, progressPath := setupTempDir(t)
+		require.NoError(t, writeTaskFile(repoRoot, strings.Replace(validYAML, "'feat: t1'", "'Add t1'", 1)))
+		backend := &mockProvider{
+			writeFile: func(root string, _ int) error {
+				return writeTaskFile(root, validYAML)
+			},
+		}
+		d := New(backend, repoRoot)
+
+		policyOpts := opts
+		policyOpts.MessagePolicy = gitx.MessagePolicy{Conventional: true}
+		require.NoError(t, d.FixTask(context.Background(), prdPath, progressPath, policyOpts))
+		assert.Equal(t, 1, backend.calls) // 1 fix, no exploration
+
+		require.NoError(t, d.FixTask(context.Background(), prdPath, progressPath, policyOpts))
+		assert.Equal(t, 1, backend.calls, "a valid task is left alone")
+	})
+
 	t.Run("fail after max retries - backend keeps writing invalid file", func(t *testing.T) {
 		repoRoot, prdPath, progressPath := setupTempDir(t)
 		backend := &mockProvider{
@@ -217,14 +260,14 @@ task:
 		require.NoError(t, os.WriteFile(taskPath, []byte(validYAML), 0644))
 
 		d := &Decomposer{repoRoot: tmpDir}
-		err := d.validateTaskFile(taskPath)
+		err := d.validateTaskFile(taskPath, gitx.MessagePolicy{})
 		assert.NoError(t, err)
 	})
 
 	t.Run("file does not exist", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		d := &Decomposer{repoRoot: tmpDir}
-		err := d.validateTaskFile(filepath.Join(tmpDir, ".turbine", "task.yaml"))
+		err := d.validateTaskFile(filepath.Join(tmpDir, ".turbine", "task.yaml"), gitx.MessagePolicy{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "task file was not created")
 	})
@@ -237,11 +280,24 @@ task:
 		require.NoError(t, os.WriteFile(taskPath, []byte(""), 0644))
 
 		d := &Decomposer{repoRoot: tmpDir}
-		err := d.validateTaskFile(taskPath)
+		err := d.validateTaskFile(taskPath, gitx.MessagePolicy{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "task file is empty")
 	})
 
+	t.Run("commit message violates policy", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		tasksDir := filepath.Join(tmpDir, ".turbine")
+		require.NoError(t, os.MkdirAll(tasksDir, 0755))
+		taskPath := filepath.Join(tasksDir, "task.yaml")
+		require.NoError(t, os.WriteFile(taskPath, []byte(validYAML), 0644))
+
+		d := &Decomposer{repoRoot: tmpDir}
+		err := d.validateTaskFile(taskPath, gitx.MessagePolicy{Conventional: true, Types: []string{"fix"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "validate commit_message")
+	})
+
 	t.Run("invalid YAML", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		tasksDir := filepath.Join(tmpDir, ".turbine")
@@ -250,7 +306,7 @@ task:
 		require.NoError(t, os.WriteFile(taskPath, []byte("invalid: yaml: :"), 0644))
 
 		d := &Decomposer{repoRoot: tmpDir}
-		err := d.validateTaskFile(taskPath)
+		err := d.validateTaskFile(taskPath, gitx.MessagePolicy{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "parse task")
 	})
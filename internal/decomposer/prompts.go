@@ -65,8 +65,10 @@ task:
   acceptance: [string] (strongly preferred; 3-5 testable statements)
   verify: [string] (optional; ordered list of shell commands as strings)
   commit_message: string (required for todo/failed; Conventional Commits first line)
+  section: string (optional; the PRD heading this task implements)
 
 Completion
+- Progress entries marked "reverted" were rolled back and are no longer in the codebase; plan that work again.
 - If the PRD is fully implemented given the progress log, set status: done.
 - Provide a short description explaining why there is no remaining work.
 - commit_message may be empty for status: done.
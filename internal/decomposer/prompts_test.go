@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	relayprovider "github.com/yarlson/turbine/internal/relay/provider"
 )
 
 func TestBuildExplorePrompt(t *testing.T) {
@@ -43,3 +44,12 @@ func TestBuildPlanFixPrompt(t *testing.T) {
 	assert.Contains(t, result, errMsg)
 	assert.Contains(t, result, "Fix Invalid .turbine/task.yaml")
 }
+
+func TestPromptPhases(t *testing.T) {
+	// The fake backend recognizes planning calls by their role prompts.
+	assert.Equal(t, relayprovider.PhaseExplore, relayprovider.Phase(buildExplorePrompt("prd", "")))
+	assert.Equal(t, relayprovider.PhasePlan, relayprovider.Phase(buildPlanPrompt("prd", "", ".turbine/task.yaml")))
+	fix := buildPlanFixPrompt("prd", "", "bad", "invalid")
+	assert.Equal(t, relayprovider.PhasePlan, relayprovider.Phase(fix))
+	assert.Contains(t, fix, "Fix Invalid")
+}
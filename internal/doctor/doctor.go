@@ -0,0 +1,309 @@
+// Package doctor diagnoses the environment Turbine runs in: git, the repository,
+// the configured backends, the config files and the .turbine layout.
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/run"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of one check. Fix says how to resolve a warning or failure.
+type Result struct {
+	Check   string
+	Status  Status
+	Message string
+	Fix     string
+}
+
+// MinGitVersion is the oldest git release Turbine is tested with.
+var MinGitVersion = [2]int{2, 25}
+
+// versionTimeout bounds each "<backend> --version" call.
+const versionTimeout = 10 * time.Second
+
+// Run runs every check from the working directory cwd. Checks that need a
+// repository are skipped outside one. profile is the --profile flag; without
+// it, the config is checked with the profile of an unfinished run.
+func Run(ctx context.Context, cwd, profile string) []Result {
+	var results []Result
+	add := func(r ...Result) { results = append(results, r...) }
+
+	if !checkGitVersion(ctx, &results) {
+		cfg, cfgResults := checkConfig("", profile)
+		add(cfgResults...)
+		add(backendResults(ctx, cfg)...)
+		return results
+	}
+
+	repoRoot, err := gitx.RepoRoot(ctx, cwd)
+	if err != nil {
+		add(Result{Check: "repository", Status: StatusFail, Message: "not inside a git repository", Fix: "Run turbine inside a git repository (git init or turbine init)"})
+		cfg, cfgResults := checkConfig("", profile)
+		add(cfgResults...)
+		add(checkIdentity(ctx, cwd, cfg))
+		add(backendResults(ctx, cfg)...)
+		return results
+	}
+
+	cfg, cfgResults := checkConfig(repoRoot, profile)
+	add(cfgResults...)
+	add(checkIdentity(ctx, repoRoot, cfg))
+	add(checkClean(ctx, repoRoot))
+	add(backendResults(ctx, cfg)...)
+	add(checkLayout(repoRoot)...)
+	add(checkIgnores(ctx, repoRoot))
+	add(checkFile(repoRoot, run.PRDRelPath, "PRD", "Import one with turbine --prd <path>"))
+	add(checkFile(repoRoot, "AGENTS.md", "AGENTS.md", "Generate it with turbine agents --prd <path>"))
+	return results
+}
+
+// Failed returns the number of failed checks.
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
+
+var gitVersionRe = regexp.MustCompile(`git version (\d+)\.(\d+)`)
+
+func checkGitVersion(ctx context.Context, results *[]Result) bool {
+	out, err := exec.CommandContext(ctx, "git", "--version").Output()
+	if err != nil {
+		*results = append(*results, Result{Check: "git", Status: StatusFail, Message: fmt.Sprintf("git not found: %v", err), Fix: "Install git 2.25 or later"})
+		return false
+	}
+	version := strings.TrimSpace(string(out))
+	m := gitVersionRe.FindStringSubmatch(version)
+	if m == nil {
+		*results = append(*results, Result{Check: "git", Status: StatusWarn, Message: fmt.Sprintf("unrecognized version %q", version)})
+		return true
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major < MinGitVersion[0] || (major == MinGitVersion[0] && minor < MinGitVersion[1]) {
+		*results = append(*results, Result{Check: "git", Status: StatusWarn, Message: version + " is older than 2.25", Fix: "Upgrade git to 2.25 or later"})
+		return true
+	}
+	*results = append(*results, Result{Check: "git", Status: StatusPass, Message: version})
+	return true
+}
+
+func checkIdentity(ctx context.Context, dir string, cfg *config.Config) Result {
+	var missing []string
+	for _, key := range []string{"user.name", "user.email"} {
+		cmd := exec.CommandContext(ctx, "git", "config", key)
+		cmd.Dir = dir
+		if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return Result{Check: "git identity", Status: StatusPass, Message: "user.name and user.email are set"}
+	}
+	if cfg != nil && cfg.Defaults.Commit.Author.Name != "" && cfg.Defaults.Commit.Author.Email != "" {
+		return Result{Check: "git identity", Status: StatusPass, Message: "savepoint commits use defaults.commit.author"}
+	}
+	fixes := make([]string, 0, len(missing))
+	for _, key := range missing {
+		example := `"Your Name"`
+		if key == "user.email" {
+			example = "you@example.com"
+		}
+		fixes = append(fixes, fmt.Sprintf("git config --global %s %s", key, example))
+	}
+	return Result{
+		Check:   "git identity",
+		Status:  StatusFail,
+		Message: strings.Join(missing, " and ") + " not set; savepoint commits will fail",
+		Fix:     strings.Join(fixes, " && "),
+	}
+}
+
+func checkClean(ctx context.Context, repoRoot string) Result {
+	if runState, exists, err := state.Load(repoRoot); err == nil && exists {
+		return Result{Check: "working tree", Status: StatusPass, Message: fmt.Sprintf("run %s in progress; turbine resumes it", runState.RunID)}
+	}
+	dirty, err := gitx.IsDirtyExcluding(ctx, repoRoot, state.LockRelPath)
+	if err != nil {
+		return Result{Check: "working tree", Status: StatusFail, Message: err.Error()}
+	}
+	if dirty {
+		return Result{Check: "working tree", Status: StatusWarn, Message: "uncommitted changes; a new run refuses to start", Fix: "Commit or stash your changes"}
+	}
+	return Result{Check: "working tree", Status: StatusPass, Message: "clean"}
+}
+
+// checkConfig loads and validates the config layers of repoRoot (global only
+// when empty) as a run would, with the profile of an unfinished run unless
+// profile is given. The returned config is nil when it cannot be loaded.
+func checkConfig(repoRoot, profile string) (*config.Config, []Result) {
+	// An unreadable run state is reported by checkLayout.
+	opts, _ := run.ConfigOptions(repoRoot, profile)
+	layered, err := config.LoadLayered(repoRoot, opts)
+	var validationErr *config.ValidationError
+	if layered == nil {
+		return nil, []Result{{Check: "config", Status: StatusFail, Message: err.Error(), Fix: "Fix the config file; turbine config validate lists every problem"}}
+	} else if errors.As(err, &validationErr) {
+		// The config loaded, so the backends can still be checked.
+		return layered.Config, []Result{{Check: "config", Status: StatusFail, Message: err.Error(), Fix: "Fix the config; turbine config show --effective shows where each value is set"}}
+	}
+
+	paths := []string{config.ResolveConfigPath()}
+	if repoRoot != "" {
+		paths = append(paths, filepath.Join(repoRoot, config.ProjectRelPath))
+	}
+	var found []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+	source := "built-in defaults"
+	if len(found) > 0 {
+		source = strings.Join(found, ", ")
+	}
+	return layered.Config, []Result{{Check: "config", Status: StatusPass, Message: source}}
+}
+
+// backendResults checks every configured backend. Only the default backend and
+// those the run may switch to fail the check; others only warn.
+func backendResults(ctx context.Context, cfg *config.Config) []Result {
+	if cfg == nil {
+		return nil
+	}
+	used := map[string]bool{cfg.Defaults.Backend: true}
+	for _, rung := range cfg.Defaults.Escalation {
+		used[rung.Backend] = true
+	}
+	for _, name := range cfg.Defaults.Failover {
+		used[name] = true
+	}
+
+	names := make([]string, 0, len(cfg.Backends))
+	for name := range cfg.Backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	results := make([]Result, 0, len(names))
+	for _, name := range names {
+		r := checkBackend(ctx, name, cfg.Backends[name])
+		if r.Status == StatusFail && !used[name] {
+			r.Status = StatusWarn
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func checkBackend(ctx context.Context, name string, b config.Backend) Result {
+	check := "backend " + name
+	typ := b.Type
+	if typ == "" {
+		typ = name
+	}
+	if typ == "fake" {
+		if b.Scenario == "" {
+			return Result{Check: check, Status: StatusFail, Message: "no scenario set", Fix: fmt.Sprintf("Set backends.%s.scenario", name)}
+		}
+		if _, err := os.Stat(b.Scenario); err != nil {
+			return Result{Check: check, Status: StatusFail, Message: err.Error(), Fix: fmt.Sprintf("Fix backends.%s.scenario", name)}
+		}
+		return Result{Check: check, Status: StatusPass, Message: "scripted scenario " + b.Scenario}
+	}
+
+	if b.Command == "" {
+		return Result{Check: check, Status: StatusFail, Message: "no command set", Fix: fmt.Sprintf("Set backends.%s.command", name)}
+	}
+	path, err := exec.LookPath(b.Command)
+	if err != nil {
+		return Result{
+			Check:   check,
+			Status:  StatusFail,
+			Message: fmt.Sprintf("%s not found on PATH", b.Command),
+			Fix:     fmt.Sprintf("Install %s or set backends.%s.command to its path", b.Command, name),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "--version")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return Result{Check: check, Status: StatusWarn, Message: fmt.Sprintf("%s found, but %s --version failed: %v", path, b.Command, err)}
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(out.String()), "\n")
+	return Result{Check: check, Status: StatusPass, Message: strings.TrimSpace(fmt.Sprintf("%s %s", path, version))}
+}
+
+func checkLayout(repoRoot string) []Result {
+	if info, err := os.Stat(filepath.Join(repoRoot, ".turbine")); err != nil || !info.IsDir() {
+		return []Result{{Check: ".turbine", Status: StatusWarn, Message: "not created yet", Fix: "Run turbine init --prd <path>"}}
+	}
+
+	results := []Result{{Check: ".turbine", Status: StatusPass, Message: "present"}}
+	if _, _, err := state.Load(repoRoot); err != nil {
+		results = append(results, Result{Check: "run state", Status: StatusFail, Message: err.Error(), Fix: "Remove .turbine/state/run.json to start a fresh run"})
+	}
+	taskPath := filepath.Join(repoRoot, run.TaskRelPath)
+	if _, err := os.Stat(taskPath); err == nil {
+		if _, err := tasks.LoadTaskFileWithBackup(taskPath, filepath.Join(repoRoot, run.TaskBackupRelPath)); err != nil {
+			results = append(results, Result{Check: "task file", Status: StatusFail, Message: err.Error(), Fix: "Fix or remove " + run.TaskRelPath})
+		}
+	}
+	return results
+}
+
+func checkIgnores(ctx context.Context, repoRoot string) Result {
+	missing, err := gitx.MissingTurbineIgnores(ctx, repoRoot)
+	if err != nil {
+		return Result{Check: "gitignore", Status: StatusFail, Message: err.Error()}
+	}
+	if len(missing) > 0 {
+		return Result{
+			Check:   "gitignore",
+			Status:  StatusWarn,
+			Message: "not ignored: " + strings.Join(missing, ", "),
+			Fix:     "Run turbine init to add them, or add them to .gitignore",
+		}
+	}
+	return Result{Check: "gitignore", Status: StatusPass, Message: "run data is ignored"}
+}
+
+func checkFile(repoRoot, rel, check, fix string) Result {
+	if _, err := os.Stat(filepath.Join(repoRoot, rel)); err != nil {
+		return Result{Check: check, Status: StatusWarn, Message: rel + " not found", Fix: fix}
+	}
+	return Result{Check: check, Status: StatusPass, Message: rel}
+}
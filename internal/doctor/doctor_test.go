@@ -0,0 +1,162 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/turbine/internal/state"
+)
+
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	repoRoot := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoRoot
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return repoRoot
+}
+
+// writeConfig writes a global config to a fresh XDG_CONFIG_HOME.
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if content == "" {
+		return
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "turbine", "turbine.yaml"), []byte(content), 0644))
+}
+
+func byCheck(results []Result) map[string]Result {
+	m := make(map[string]Result, len(results))
+	for _, r := range results {
+		m[r.Check] = r
+	}
+	return m
+}
+
+func TestRun_Healthy(t *testing.T) {
+	repoRoot := setupRepo(t)
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "agent"), []byte("#!/bin/sh\necho agent 1.2.3\n"), 0755))
+	writeConfig(t, `
+defaults:
+  backend: agent
+backends:
+  agent:
+    type: exec
+    command: `+filepath.Join(bin, "agent")+`
+    models: {fast: small, slow: large}
+`)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "prd.md"), []byte("# PRD\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "AGENTS.md"), []byte("# Agents\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".gitignore"), []byte(".turbine/\n"), 0644))
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", "setup"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoRoot
+		require.NoError(t, cmd.Run())
+	}
+
+	results := Run(context.Background(), repoRoot, "")
+	for _, r := range results {
+		if strings.HasPrefix(r.Check, "backend ") && r.Check != "backend agent" {
+			continue // Built-in backends that are not installed only warn.
+		}
+		assert.Equal(t, StatusPass, r.Status, "%s: %s", r.Check, r.Message)
+	}
+	assert.Contains(t, byCheck(results)["backend agent"].Message, "agent 1.2.3")
+	assert.Zero(t, Failed(results))
+}
+
+func TestRun_Problems(t *testing.T) {
+	repoRoot := setupRepo(t)
+	writeConfig(t, `
+defaults:
+  backend: missing
+  failover: [spare]
+backends:
+  spare:
+    type: exec
+    command: turbine-no-such-binary
+    models: {fast: small, slow: large}
+  extra:
+    type: exec
+    command: turbine-no-such-binary
+    models: {fast: small, slow: large}
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "dirty.txt"), []byte("x"), 0644))
+
+	results := byCheck(Run(context.Background(), repoRoot, ""))
+
+	assert.Equal(t, StatusFail, results["config"].Status)
+	assert.Contains(t, results["config"].Message, `defaults.backend: backend "missing" is not configured`)
+	assert.Equal(t, StatusFail, results["backend spare"].Status, "failover backends must be installed")
+	assert.Contains(t, results["backend spare"].Fix, "Install turbine-no-such-binary")
+	assert.Equal(t, StatusWarn, results["backend extra"].Status, "unused backends only warn")
+	assert.Equal(t, StatusWarn, results["working tree"].Status)
+	assert.Equal(t, StatusWarn, results[".turbine"].Status)
+	assert.Equal(t, StatusWarn, results["PRD"].Status)
+	assert.Equal(t, StatusWarn, results["AGENTS.md"].Status)
+	assert.Contains(t, results["AGENTS.md"].Fix, "turbine agents")
+}
+
+func TestRun_CorruptFiles(t *testing.T) {
+	repoRoot := setupRepo(t)
+	writeConfig(t, "defaults: [")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".turbine", "state"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "state", "run.json"), []byte("{"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "config.yaml"), []byte("hooks: ["), 0644))
+
+	results := byCheck(Run(context.Background(), repoRoot, ""))
+	assert.Equal(t, StatusFail, results["config"].Status)
+	assert.Contains(t, results["config"].Message, "turbine.yaml")
+	assert.Equal(t, StatusFail, results["run state"].Status)
+	assert.Contains(t, results["run state"].Fix, "run.json")
+
+	writeConfig(t, "")
+	results = byCheck(Run(context.Background(), repoRoot, ""))
+	assert.Equal(t, StatusFail, results["config"].Status)
+	assert.Contains(t, results["config"].Message, ".turbine/config.yaml")
+}
+
+func TestRun_NotARepo(t *testing.T) {
+	writeConfig(t, "")
+	results := byCheck(Run(context.Background(), t.TempDir(), ""))
+	assert.Equal(t, StatusFail, results["repository"].Status)
+	assert.Contains(t, results["repository"].Fix, "git init")
+}
+
+func TestRun_RunProfile(t *testing.T) {
+	repoRoot := setupRepo(t)
+	t.Setenv("TURBINE_PROFILE", "")
+	writeConfig(t, `profiles:
+  broken:
+    backend: missing
+`)
+	profile := "broken"
+	require.NoError(t, state.Save(repoRoot, &state.RunState{RunID: "run-1", Profile: &profile}))
+
+	results := byCheck(Run(context.Background(), repoRoot, ""))
+	assert.Equal(t, StatusFail, results["config"].Status, "the config is checked as the run resumes with it")
+	assert.Contains(t, results["config"].Message, `backend "missing" is not configured`)
+
+	results = byCheck(Run(context.Background(), repoRoot, "other"))
+	assert.Contains(t, results["config"].Message, `profile "other" is not configured`)
+}
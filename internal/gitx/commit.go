@@ -3,26 +3,65 @@ package gitx
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
 
-// CommitSavePoint creates a git commit with the given subject and footer.
+// Identity is a git author or committer identity.
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// CommitOptions configures the body, trailers, identity and signing of a savepoint commit.
+type CommitOptions struct {
+	Body     string
+	Trailers []string
+	// Author overrides the git author identity when set.
+	Author Identity
+	// Committer overrides the git committer identity; defaults to Author when empty.
+	Committer Identity
+	// Sign selects commit signing: "" (git config), "gpg", "ssh" or "off".
+	Sign       string
+	SigningKey string
+	// Paths limits the commit to changes under these paths; all changes when empty.
+	Paths []string
+}
+
+// CommitSavePoint creates a git commit with the given subject and trailer lines.
 // It includes all changes in the working tree (git add -A).
-func CommitSavePoint(ctx context.Context, repoRoot, subjectLine, footerLine string) (string, error) {
+// Trailer lines are written as a single footer block, e.g. "Turbine: T-001".
+func CommitSavePoint(ctx context.Context, repoRoot, subjectLine string, trailerLines ...string) (string, error) {
+	return CommitSavePointWithOptions(ctx, repoRoot, subjectLine, CommitOptions{Trailers: trailerLines})
+}
+
+// CommitSavePointWithOptions creates a git commit of all working tree changes
+// using the given subject and options, and returns the new commit hash.
+func CommitSavePointWithOptions(ctx context.Context, repoRoot, subjectLine string, opts CommitOptions) (string, error) {
+	commitArgs, err := commitArgs(opts)
+	if err != nil {
+		return "", err
+	}
+
 	// 1. Stage all changes
-	addCmd := exec.CommandContext(ctx, "git", "add", "-A")
+	var pathspec []string
+	if len(opts.Paths) > 0 {
+		pathspec = append([]string{"--"}, opts.Paths...)
+	}
+	addCmd := exec.CommandContext(ctx, "git", append([]string{"add", "-A"}, pathspec...)...)
 	addCmd.Dir = repoRoot
 	if err := addCmd.Run(); err != nil {
 		return "", fmt.Errorf("git add: %w", err)
 	}
 
 	// 2. Create commit message
-	commitMsg := fmt.Sprintf("%s\n\n%s", subjectLine, footerLine)
+	commitMsg := BuildCommitMessage(subjectLine, opts.Body, opts.Trailers)
 
 	// 3. Commit
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", commitMsg)
+	commitCmd := exec.CommandContext(ctx, "git", append(append(commitArgs, "-m", commitMsg), pathspec...)...)
 	commitCmd.Dir = repoRoot
+	commitCmd.Env = identityEnv(opts.Author, opts.Committer)
 	if output, err := commitCmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("git commit: %w (output: %s)", err, string(output))
 	}
@@ -37,3 +76,84 @@ func CommitSavePoint(ctx context.Context, repoRoot, subjectLine, footerLine stri
 
 	return strings.TrimSpace(string(hashOutput)), nil
 }
+
+// BuildCommitMessage joins subject, optional body and trailer block into a commit message.
+func BuildCommitMessage(subject, body string, trailers []string) string {
+	parts := []string{strings.TrimSpace(subject)}
+	if body = strings.TrimSpace(body); body != "" {
+		parts = append(parts, body)
+	}
+	if len(trailers) > 0 {
+		parts = append(parts, strings.Join(trailers, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// CommitTree creates a commit with the tree of treeish on top of parent without touching
+// HEAD, the index or the working tree, and returns its hash.
+func CommitTree(ctx context.Context, repoRoot, treeish, parent, message string, opts CommitOptions) (string, error) {
+	args, err := gitSubcommandArgs("commit-tree", opts)
+	if err != nil {
+		return "", err
+	}
+	args = append(args, treeish+"^{tree}", "-p", parent, "-m", message)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoRoot
+	cmd.Env = identityEnv(opts.Author, opts.Committer)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func commitArgs(opts CommitOptions) ([]string, error) {
+	return gitSubcommandArgs("commit", opts)
+}
+
+// gitSubcommandArgs returns git arguments for a commit-creating subcommand with signing applied.
+func gitSubcommandArgs(subcommand string, opts CommitOptions) ([]string, error) {
+	var args []string
+	switch opts.Sign {
+	case "":
+		return []string{subcommand}, nil
+	case "off":
+		return []string{subcommand, "--no-gpg-sign"}, nil
+	case "gpg":
+		args = append(args, "-c", "gpg.format=openpgp")
+	case "ssh":
+		args = append(args, "-c", "gpg.format=ssh")
+	default:
+		return nil, fmt.Errorf("unsupported commit signing mode %q (expected: gpg, ssh, off)", opts.Sign)
+	}
+	if opts.SigningKey != "" {
+		args = append(args, "-c", "user.signingkey="+opts.SigningKey)
+	}
+	return append(args, subcommand, "-S"), nil
+}
+
+// identityEnv returns the process environment with author/committer overrides applied.
+// It returns nil (inherit) when no identity is configured.
+func identityEnv(author, committer Identity) []string {
+	if committer == (Identity{}) {
+		committer = author
+	}
+	var env []string
+	if author.Name != "" {
+		env = append(env, "GIT_AUTHOR_NAME="+author.Name)
+	}
+	if author.Email != "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+author.Email)
+	}
+	if committer.Name != "" {
+		env = append(env, "GIT_COMMITTER_NAME="+committer.Name)
+	}
+	if committer.Email != "" {
+		env = append(env, "GIT_COMMITTER_EMAIL="+committer.Email)
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), env...)
+}
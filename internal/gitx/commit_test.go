@@ -60,3 +60,96 @@ func TestCommitSavePoint(t *testing.T) {
 	expectedMsg := subject + "\n\n" + footer
 	assert.Equal(t, expectedMsg, strings.TrimSpace(string(msgOutput)))
 }
+
+func TestCommitSavePoint_MultipleTrailers(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	err := os.WriteFile(filepath.Join(repoRoot, "hello.txt"), []byte("hello"), 0644)
+	require.NoError(t, err)
+
+	_, err = CommitSavePoint(ctx, repoRoot, "feat: hello", "Turbine: T-001", "Turbine-Run: run-1", "Turbine-Stroke: 2")
+	require.NoError(t, err)
+
+	cmd := exec.Command("git", "log", "-1", "--pretty=%(trailers:key=Turbine-Run,valueonly)")
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", strings.TrimSpace(string(out)))
+
+	cmd = exec.Command("git", "log", "-1", "--pretty=%B")
+	cmd.Dir = repoRoot
+	out, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "feat: hello\n\nTurbine: T-001\nTurbine-Run: run-1\nTurbine-Stroke: 2", strings.TrimSpace(string(out)))
+}
+
+func TestCommitSavePointWithOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dedicated identity and body", func(t *testing.T) {
+		repoRoot := setupTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+
+		_, err := CommitSavePointWithOptions(ctx, repoRoot, "feat: a", CommitOptions{
+			Body:     "Details here.",
+			Trailers: []string{"Turbine: T-001"},
+			Author:   Identity{Name: "Turbine Bot", Email: "bot@example.com"},
+			Sign:     "off",
+		})
+		require.NoError(t, err)
+
+		cmd := exec.Command("git", "log", "-1", "--pretty=%an <%ae>|%cn <%ce>")
+		cmd.Dir = repoRoot
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, "Turbine Bot <bot@example.com>|Turbine Bot <bot@example.com>", strings.TrimSpace(string(out)))
+
+		cmd = exec.Command("git", "log", "-1", "--pretty=%B")
+		cmd.Dir = repoRoot
+		out, err = cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, "feat: a\n\nDetails here.\n\nTurbine: T-001", strings.TrimSpace(string(out)))
+	})
+
+	t.Run("separate committer", func(t *testing.T) {
+		repoRoot := setupTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+
+		_, err := CommitSavePointWithOptions(ctx, repoRoot, "feat: a", CommitOptions{
+			Author:    Identity{Name: "Author", Email: "author@example.com"},
+			Committer: Identity{Name: "Committer", Email: "committer@example.com"},
+		})
+		require.NoError(t, err)
+
+		cmd := exec.Command("git", "log", "-1", "--pretty=%an|%cn")
+		cmd.Dir = repoRoot
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, "Author|Committer", strings.TrimSpace(string(out)))
+	})
+
+	t.Run("limited to paths", func(t *testing.T) {
+		repoRoot := setupTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "b.txt"), []byte("b"), 0644))
+
+		_, err := CommitSavePointWithOptions(ctx, repoRoot, "chore: a", CommitOptions{Paths: []string{"a.txt"}})
+		require.NoError(t, err)
+
+		cmd := exec.Command("git", "show", "--name-only", "--pretty=", "HEAD")
+		cmd.Dir = repoRoot
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, "a.txt", strings.TrimSpace(string(out)))
+		dirty, err := IsDirty(ctx, repoRoot)
+		require.NoError(t, err)
+		assert.True(t, dirty, "b.txt is left uncommitted")
+	})
+
+	t.Run("unsupported signing mode", func(t *testing.T) {
+		repoRoot := setupTestRepo(t)
+		_, err := CommitSavePointWithOptions(ctx, repoRoot, "feat: a", CommitOptions{Sign: "pgp"})
+		assert.ErrorContains(t, err, "unsupported commit signing mode")
+	})
+}
@@ -19,6 +19,16 @@ func RepoRoot(ctx context.Context, cwd string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// Init creates a git repository in dir and returns its root.
+func Init(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "init")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git init: %w (output: %s)", err, string(output))
+	}
+	return RepoRoot(ctx, dir)
+}
+
 // IsDirty returns true if the git repository at repoRoot has uncommitted changes.
 func IsDirty(ctx context.Context, repoRoot string) (bool, error) {
 	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
@@ -30,6 +40,46 @@ func IsDirty(ctx context.Context, repoRoot string) (bool, error) {
 	return len(strings.TrimSpace(string(out))) > 0, nil
 }
 
+// IsDirtyExcluding reports uncommitted changes outside the given paths.
+func IsDirtyExcluding(ctx context.Context, repoRoot string, exclude ...string) (bool, error) {
+	args := []string{"status", "--porcelain", "--", "."}
+	for _, path := range exclude {
+		args = append(args, ":(exclude)"+path)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("could not check repository status: %w", err)
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+// ChangedPaths returns the files under paths with uncommitted changes,
+// including untracked files that are not ignored.
+func ChangedPaths(ctx context.Context, repoRoot string, paths ...string) ([]string, error) {
+	args := append([]string{"status", "--porcelain", "-z", "--untracked-files=all", "--"}, paths...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not check repository status: %w", err)
+	}
+	var changed []string
+	entries := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		changed = append(changed, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++ // The original path follows a rename or copy.
+		}
+	}
+	return changed, nil
+}
+
 // CurrentHash returns the current commit hash (HEAD).
 func CurrentHash(ctx context.Context, repoRoot string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
@@ -5,9 +5,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRepoRoot(t *testing.T) {
@@ -87,3 +89,38 @@ func runGit(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %v\nOutput: %s", args, err, out)
 	}
 }
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	require.NoError(t, err, "git %v", args)
+	return strings.TrimSpace(string(out))
+}
+
+func TestInitAndChangedPaths(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	root, err := Init(ctx, tmp)
+	require.NoError(t, err)
+	evalTmp, _ := filepath.EvalSymlinks(tmp)
+	evalRoot, _ := filepath.EvalSymlinks(root)
+	assert.Equal(t, evalTmp, evalRoot)
+
+	runGit(t, tmp, "config", "user.email", "you@example.com")
+	runGit(t, tmp, "config", "user.name", "Your Name")
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, ".gitignore"), []byte("ignored.txt\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "kept.txt"), []byte("kept"), 0644))
+	runGit(t, tmp, "add", ".")
+	runGit(t, tmp, "commit", "-m", "initial", "--no-gpg-sign")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, ".gitignore"), []byte("ignored.txt\nother\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "new.txt"), []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "ignored.txt"), []byte("x"), 0644))
+
+	changed, err := ChangedPaths(ctx, tmp, ".gitignore", "kept.txt", "new.txt", "ignored.txt", "missing.txt")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{".gitignore", "new.txt"}, changed)
+}
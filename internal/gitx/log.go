@@ -0,0 +1,74 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Commit is a single entry of the first-parent history.
+type Commit struct {
+	Hash    string
+	Parent  string // First parent; empty for a root commit
+	Subject string
+	// Trailers maps trailer keys to values, e.g. "Turbine" -> "T-001".
+	Trailers map[string]string
+}
+
+// Log returns the first-parent history from HEAD, newest first.
+// limit caps the number of commits (0 = all).
+func Log(ctx context.Context, repoRoot string, limit int) ([]Commit, error) {
+	return LogRange(ctx, repoRoot, "HEAD", limit)
+}
+
+// LogRange returns the first-parent history for a revision range (e.g. "HEAD" or "A..B"), newest first.
+// limit caps the number of commits (0 = all).
+func LogRange(ctx context.Context, repoRoot, revRange string, limit int) ([]Commit, error) {
+	args := []string{"log", "--first-parent", "--format=%H%x1f%P%x1f%s%x1f%(trailers:only,unfold)%x1e"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, revRange, "--")
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(out), "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("git log: unexpected record %q", record)
+		}
+		parent, _, _ := strings.Cut(fields[1], " ")
+		commits = append(commits, Commit{
+			Hash:     fields[0],
+			Parent:   parent,
+			Subject:  fields[2],
+			Trailers: parseTrailers(fields[3]),
+		})
+	}
+
+	return commits, nil
+}
+
+func parseTrailers(block string) map[string]string {
+	trailers := make(map[string]string)
+	for _, line := range strings.Split(block, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		trailers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return trailers
+}
@@ -0,0 +1,110 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+	first, err := CommitSavePoint(ctx, repoRoot, "chore: manual")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "b.txt"), []byte("b"), 0644))
+	second, err := CommitSavePoint(ctx, repoRoot, "feat: b", "Turbine: T-001", "Turbine-Run: run-1")
+	require.NoError(t, err)
+
+	commits, err := Log(ctx, repoRoot, 0)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	assert.Equal(t, second, commits[0].Hash)
+	assert.Equal(t, first, commits[0].Parent)
+	assert.Equal(t, "feat: b", commits[0].Subject)
+	assert.Equal(t, "T-001", commits[0].Trailers["Turbine"])
+	assert.Equal(t, "run-1", commits[0].Trailers["Turbine-Run"])
+
+	assert.Equal(t, first, commits[1].Hash)
+	assert.Empty(t, commits[1].Parent)
+	assert.Empty(t, commits[1].Trailers)
+
+	limited, err := Log(ctx, repoRoot, 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	ranged, err := LogRange(ctx, repoRoot, first+"..HEAD", 0)
+	require.NoError(t, err)
+	require.Len(t, ranged, 1)
+	assert.Equal(t, second, ranged[0].Hash)
+}
+
+func TestRevert(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+	_, err := CommitSavePoint(ctx, repoRoot, "chore: base")
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "b.txt"), []byte("b"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "progress.md"), []byte("done\n"), 0644))
+	_, err = CommitSavePoint(ctx, repoRoot, "feat: b", "Turbine: T-001")
+	require.NoError(t, err)
+
+	commits, err := Log(ctx, repoRoot, 1)
+	require.NoError(t, err)
+
+	// Staged changes outside the reverted paths stay out of the revert commit.
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "progress.md"), []byte("done\nmore\n"), 0644))
+	runGit(t, repoRoot, "add", ".turbine/progress.md")
+
+	hash, err := Revert(ctx, repoRoot, commits[0], CommitOptions{
+		Trailers: []string{"Turbine-Reverts: " + commits[0].Hash},
+		Author:   Identity{Name: "Turbine Bot", Email: "bot@example.com"},
+	}, ".turbine")
+	require.NoError(t, err)
+	assert.NotEqual(t, commits[0].Hash, hash)
+
+	_, err = os.Stat(filepath.Join(repoRoot, "b.txt"))
+	assert.True(t, os.IsNotExist(err))
+	// Excluded path is kept
+	assert.FileExists(t, filepath.Join(repoRoot, ".turbine", "progress.md"))
+
+	head, err := Log(ctx, repoRoot, 1)
+	require.NoError(t, err)
+	assert.Equal(t, `Revert "feat: b"`, head[0].Subject)
+	assert.Equal(t, commits[0].Hash, head[0].Trailers["Turbine-Reverts"])
+	assert.Equal(t, "Turbine Bot <bot@example.com>", gitOutput(t, repoRoot, "log", "-1", "--format=%an <%ae>"))
+	assert.Equal(t, "b.txt", gitOutput(t, repoRoot, "show", "--name-only", "--format=", "HEAD"))
+
+	dirty, err := IsDirtyExcluding(ctx, repoRoot, ".turbine")
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, "M  .turbine/progress.md", gitOutput(t, repoRoot, "status", "--porcelain"))
+}
+
+func TestIsDirtyExcluding(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "progress.md"), []byte("x"), 0644))
+
+	dirty, err := IsDirtyExcluding(ctx, repoRoot, ".turbine")
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+	dirty, err = IsDirtyExcluding(ctx, repoRoot, ".turbine")
+	require.NoError(t, err)
+	assert.True(t, dirty)
+}
@@ -0,0 +1,69 @@
+package gitx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultConventionalTypes are the commit types accepted when a policy lists none.
+var DefaultConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var conventionalSubject = regexp.MustCompile(`^([a-z]+)(?:\(([^()]+)\))?!?: \S.*$`)
+
+// MessagePolicy describes the conventions a savepoint commit message must follow.
+type MessagePolicy struct {
+	Conventional bool
+	Types        []string
+	Scopes       []string
+	MaxSubject   int
+}
+
+// ParseConventional returns the type and scope of a Conventional Commits subject.
+func ParseConventional(subject string) (typ, scope string, ok bool) {
+	m := conventionalSubject.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// Validate checks the subject line of msg against the policy.
+func (p MessagePolicy) Validate(msg string) error {
+	subject, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return fmt.Errorf("commit message is empty")
+	}
+
+	if p.MaxSubject > 0 {
+		if n := utf8.RuneCountInString(subject); n > p.MaxSubject {
+			return fmt.Errorf("commit subject is %d characters, max is %d: %q", n, p.MaxSubject, subject)
+		}
+	}
+
+	if !p.Conventional {
+		return nil
+	}
+
+	m := conventionalSubject.FindStringSubmatch(subject)
+	if m == nil {
+		return fmt.Errorf("commit subject %q does not follow Conventional Commits (type(scope): description)", subject)
+	}
+
+	types := p.Types
+	if len(types) == 0 {
+		types = DefaultConventionalTypes
+	}
+	if !slices.Contains(types, m[1]) {
+		return fmt.Errorf("commit type %q is not allowed (expected one of: %s)", m[1], strings.Join(types, ", "))
+	}
+
+	if m[2] != "" && len(p.Scopes) > 0 && !slices.Contains(p.Scopes, m[2]) {
+		return fmt.Errorf("commit scope %q is not allowed (expected one of: %s)", m[2], strings.Join(p.Scopes, ", "))
+	}
+
+	return nil
+}
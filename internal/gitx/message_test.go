@@ -0,0 +1,55 @@
+package gitx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagePolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  MessagePolicy
+		msg     string
+		wantErr string
+	}{
+		{name: "no policy accepts anything", policy: MessagePolicy{}, msg: "did stuff"},
+		{name: "empty message", policy: MessagePolicy{}, msg: "  \n", wantErr: "commit message is empty"},
+		{name: "subject too long", policy: MessagePolicy{MaxSubject: 10}, msg: "feat: a very long subject", wantErr: "max is 10"},
+		{name: "body ignored for length", policy: MessagePolicy{MaxSubject: 10}, msg: "feat: ok\n\nlong long long long body"},
+		{name: "conventional ok", policy: MessagePolicy{Conventional: true}, msg: "feat(api): add endpoint"},
+		{name: "conventional breaking", policy: MessagePolicy{Conventional: true}, msg: "fix!: drop flag"},
+		{name: "not conventional", policy: MessagePolicy{Conventional: true}, msg: "Add endpoint", wantErr: "does not follow Conventional Commits"},
+		{name: "type not allowed", policy: MessagePolicy{Conventional: true, Types: []string{"feat", "fix"}}, msg: "chore: tidy", wantErr: `commit type "chore" is not allowed`},
+		{name: "default types", policy: MessagePolicy{Conventional: true}, msg: "wip: stuff", wantErr: `commit type "wip" is not allowed`},
+		{name: "scope not allowed", policy: MessagePolicy{Conventional: true, Scopes: []string{"api"}}, msg: "feat(ui): button", wantErr: `commit scope "ui" is not allowed`},
+		{name: "scope optional", policy: MessagePolicy{Conventional: true, Scopes: []string{"api"}}, msg: "feat: button"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.msg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseConventional(t *testing.T) {
+	typ, scope, ok := ParseConventional("feat(api)!: add endpoint")
+	assert.True(t, ok)
+	assert.Equal(t, "feat", typ)
+	assert.Equal(t, "api", scope)
+
+	_, _, ok = ParseConventional("Add endpoint")
+	assert.False(t, ok)
+}
+
+func TestBuildCommitMessage(t *testing.T) {
+	assert.Equal(t, "feat: x", BuildCommitMessage("feat: x", "", nil))
+	assert.Equal(t, "feat: x\n\nTurbine: T-1", BuildCommitMessage("feat: x", " ", []string{"Turbine: T-1"}))
+	assert.Equal(t, "feat: x\n\nbody\n\nTurbine: T-1\nTurbine-Run: r", BuildCommitMessage("feat: x", "body\n", []string{"Turbine: T-1", "Turbine-Run: r"}))
+}
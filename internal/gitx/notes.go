@@ -0,0 +1,31 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// AddNote attaches content as a git note to commitHash under the given notes ref.
+// An existing note for the same commit is overwritten.
+func AddNote(ctx context.Context, repoRoot, notesRef, commitHash, content string) error {
+	cmd := exec.CommandContext(ctx, "git", "notes", "--ref", notesRef, "add", "-f", "-F", "-", commitHash)
+	cmd.Dir = repoRoot
+	cmd.Stdin = strings.NewReader(content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git notes add: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// ShowNote returns the note attached to commitHash under the given notes ref.
+func ShowNote(ctx context.Context, repoRoot, notesRef, commitHash string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "notes", "--ref", notesRef, "show", commitHash)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git notes show: %w", err)
+	}
+	return string(out), nil
+}
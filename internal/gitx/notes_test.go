@@ -0,0 +1,35 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddNote(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "hello.txt"), []byte("hello"), 0644))
+	hash, err := CommitSavePoint(ctx, repoRoot, "feat: hello", "Turbine: T-001")
+	require.NoError(t, err)
+
+	require.NoError(t, AddNote(ctx, repoRoot, "refs/notes/turbine", hash, "first note\n"))
+	note, err := ShowNote(ctx, repoRoot, "refs/notes/turbine", hash)
+	require.NoError(t, err)
+	assert.Equal(t, "first note\n", note)
+
+	// Overwrites the existing note
+	require.NoError(t, AddNote(ctx, repoRoot, "refs/notes/turbine", hash, "second note\n"))
+	note, err = ShowNote(ctx, repoRoot, "refs/notes/turbine", hash)
+	require.NoError(t, err)
+	assert.Equal(t, "second note\n", note)
+
+	// Default notes ref is untouched
+	_, err = ShowNote(ctx, repoRoot, "refs/notes/commits", hash)
+	assert.Error(t, err)
+}
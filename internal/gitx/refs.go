@@ -0,0 +1,40 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// UpdateRef points ref at commitHash, creating it if needed.
+func UpdateRef(ctx context.Context, repoRoot, ref, commitHash string) error {
+	cmd := exec.CommandContext(ctx, "git", "update-ref", ref, commitHash)
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git update-ref %s: %w (output: %s)", ref, err, string(out))
+	}
+	return nil
+}
+
+// RemoteRefsContaining returns the remote-tracking refs that contain commitHash.
+// A non-empty result means the commit has been pushed.
+func RemoteRefsContaining(ctx context.Context, repoRoot, commitHash string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(refname:short)", "--contains", commitHash, "refs/remotes")
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// ResetSoft moves HEAD (and the current branch) to commitHash, keeping the index and working tree.
+func ResetSoft(ctx context.Context, repoRoot, commitHash string) error {
+	cmd := exec.CommandContext(ctx, "git", "reset", "--soft", commitHash)
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset soft to %s: %w (output: %s)", commitHash, err, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitTreeAndRefs(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a"), 0644))
+	base, err := CommitSavePoint(ctx, repoRoot, "chore: base")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "b.txt"), []byte("b"), 0644))
+	_, err = CommitSavePoint(ctx, repoRoot, "feat: b")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "c.txt"), []byte("c"), 0644))
+	head, err := CommitSavePoint(ctx, repoRoot, "feat: c")
+	require.NoError(t, err)
+
+	require.NoError(t, UpdateRef(ctx, repoRoot, "refs/turbine/backup/test", head))
+
+	squashed, err := CommitTree(ctx, repoRoot, head, base, "feat: b and c", CommitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ResetSoft(ctx, repoRoot, squashed))
+
+	commits, err := Log(ctx, repoRoot, 0)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "feat: b and c", commits[0].Subject)
+	assert.Equal(t, base, commits[0].Parent)
+
+	dirty, err := IsDirty(ctx, repoRoot)
+	require.NoError(t, err)
+	assert.False(t, dirty)
+
+	backupLog, err := LogRange(ctx, repoRoot, "refs/turbine/backup/test", 1)
+	require.NoError(t, err)
+	assert.Equal(t, head, backupLog[0].Hash)
+
+	remotes, err := RemoteRefsContaining(ctx, repoRoot, base)
+	require.NoError(t, err)
+	assert.Empty(t, remotes)
+
+	runGit(t, repoRoot, "update-ref", "refs/remotes/origin/main", base)
+	remotes, err = RemoteRefsContaining(ctx, repoRoot, base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"origin/main"}, remotes)
+}
@@ -0,0 +1,66 @@
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Revert creates a commit that undoes commit, leaving paths matching exclude untouched.
+// Excluded paths let callers keep bookkeeping files (e.g. .turbine/) that later commits appended to.
+// The commit uses the identity, signing and trailers of opts and contains only the
+// reverted paths, so other staged changes are left out of it.
+func Revert(ctx context.Context, repoRoot string, commit Commit, opts CommitOptions, exclude ...string) (string, error) {
+	if commit.Parent == "" {
+		return "", fmt.Errorf("cannot revert root commit %s", commit.Hash)
+	}
+	args, err := commitArgs(opts)
+	if err != nil {
+		return "", err
+	}
+
+	pathspec := []string{"--", "."}
+	for _, path := range exclude {
+		pathspec = append(pathspec, ":(exclude)"+path)
+	}
+	diffCmd := exec.CommandContext(ctx, "git", append([]string{"diff", "--binary", commit.Hash, commit.Parent}, pathspec...)...)
+	diffCmd.Dir = repoRoot
+	patch, err := diffCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff %s: %w", commit.Hash, err)
+	}
+	namesCmd := exec.CommandContext(ctx, "git", append([]string{"diff", "--name-only", "-z", "--no-renames", commit.Hash, commit.Parent}, pathspec...)...)
+	namesCmd.Dir = repoRoot
+	names, err := namesCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff %s: %w", commit.Hash, err)
+	}
+
+	if len(patch) > 0 {
+		applyCmd := exec.CommandContext(ctx, "git", "apply", "--index", "--3way", "-")
+		applyCmd.Dir = repoRoot
+		applyCmd.Stdin = bytes.NewReader(patch)
+		if out, err := applyCmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("revert %s: %w (output: %s)", commit.Hash, err, string(out))
+		}
+	}
+
+	// --only commits just the listed paths, or nothing when there are none.
+	msg := BuildCommitMessage(fmt.Sprintf("Revert \"%s\"", commit.Subject), fmt.Sprintf("This reverts commit %s.", commit.Hash), opts.Trailers)
+	args = append(args, "--only", "--allow-empty", "-m", msg, "--")
+	for _, name := range strings.Split(string(names), "\x00") {
+		if name != "" {
+			args = append(args, name)
+		}
+	}
+	commitCmd := exec.CommandContext(ctx, "git", args...)
+	commitCmd.Dir = repoRoot
+	commitCmd.Env = identityEnv(opts.Author, opts.Committer)
+	if out, err := commitCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git commit: %w (output: %s)", err, string(out))
+	}
+
+	return CurrentHash(ctx, repoRoot)
+}
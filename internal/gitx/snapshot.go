@@ -0,0 +1,79 @@
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotTree writes the working tree (tracked and untracked files not ignored,
+// outside the excluded paths) to the object database and returns its tree hash.
+// It uses a temporary index, so the real index is left untouched.
+func SnapshotTree(ctx context.Context, repoRoot string, exclude ...string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "turbine-index-")
+	if err != nil {
+		return "", fmt.Errorf("create temporary index: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	indexPath := filepath.Join(tmpDir, "index")
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexPath)
+	addCmd := exec.CommandContext(ctx, "git", "add", "-A", "--", ".")
+	addCmd.Dir = repoRoot
+	addCmd.Env = env
+	if out, err := addCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git add to temporary index: %w (output: %s)", err, string(out))
+	}
+
+	// Excluded paths are dropped after adding: git add refuses exclude pathspecs
+	// that name ignored paths.
+	if len(exclude) > 0 {
+		args := append([]string{"rm", "-r", "-q", "--cached", "--ignore-unmatch", "--"}, exclude...)
+		rmCmd := exec.CommandContext(ctx, "git", args...)
+		rmCmd.Dir = repoRoot
+		rmCmd.Env = env
+		if out, err := rmCmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git rm from temporary index: %w (output: %s)", err, string(out))
+		}
+	}
+
+	treeCmd := exec.CommandContext(ctx, "git", "write-tree")
+	treeCmd.Dir = repoRoot
+	treeCmd.Env = env
+	out, err := treeCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git write-tree: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// DiffTrees returns a binary patch turning tree from into tree to.
+func DiffTrees(ctx context.Context, repoRoot, from, to string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--binary", "--no-renames", "--no-color", from, to)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff %s %s: %w", from, to, err)
+	}
+	return string(out), nil
+}
+
+// ApplyPatch applies a patch produced by DiffTrees to the working tree.
+func ApplyPatch(ctx context.Context, repoRoot, patch string) error {
+	if patch == "" {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "apply", "--binary", "--whitespace=nowarn", "-")
+	cmd.Dir = repoRoot
+	cmd.Stdin = strings.NewReader(patch)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git apply: %w (output: %s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
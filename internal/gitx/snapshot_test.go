@@ -0,0 +1,78 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotDiffApply(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a\n"), 0644))
+	_, err := CommitSavePoint(ctx, repoRoot, "chore: base")
+	require.NoError(t, err)
+
+	before, err := SnapshotTree(ctx, repoRoot, ".turbine/runs")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "a.txt"), []byte("a\nb\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, "dir"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "dir", "new.bin"), []byte{0, 1, 2}, 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".turbine", "runs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "runs", "log"), []byte("x"), 0644))
+
+	after, err := SnapshotTree(ctx, repoRoot, ".turbine/runs")
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+
+	patch, err := DiffTrees(ctx, repoRoot, before, after)
+	require.NoError(t, err)
+	assert.Contains(t, patch, "a.txt")
+	assert.Contains(t, patch, "dir/new.bin")
+	assert.NotContains(t, patch, ".turbine/runs", "excluded paths are not captured")
+
+	dirty, err := IsDirty(ctx, repoRoot)
+	require.NoError(t, err)
+	assert.True(t, dirty, "the real index is untouched")
+
+	// Apply the patch to a clean checkout of the same commit.
+	require.NoError(t, ResetHard(ctx, repoRoot, "HEAD"))
+	require.NoError(t, os.Remove(filepath.Join(repoRoot, "dir", "new.bin")))
+	require.NoError(t, ApplyPatch(ctx, repoRoot, patch))
+
+	data, err := os.ReadFile(filepath.Join(repoRoot, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(data))
+	data, err = os.ReadFile(filepath.Join(repoRoot, "dir", "new.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, data)
+
+	replayed, err := SnapshotTree(ctx, repoRoot, ".turbine/runs")
+	require.NoError(t, err)
+	assert.Equal(t, after, replayed)
+}
+
+func TestSnapshotTree_IgnoredExclude(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := setupTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".gitignore"), []byte(".turbine/runs/\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".turbine", "runs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "runs", "log"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, ".turbine", "task.yaml"), []byte("task"), 0644))
+
+	tree, err := SnapshotTree(ctx, repoRoot, ".turbine/runs", ".turbine/state")
+	require.NoError(t, err, "excluding ignored or missing paths is not an error")
+
+	cmd := exec.Command("git", "ls-tree", "-r", "--name-only", tree)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, ".gitignore\n.turbine/task.yaml\n", string(out))
+}
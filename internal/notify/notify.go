@@ -0,0 +1,147 @@
+// Package notify delivers run lifecycle events to webhooks and shell commands.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/yarlson/turbine/internal/config"
+)
+
+// Notification events.
+const (
+	EventTaskDone       = "task_done"
+	EventTaskFailed     = "task_failed"
+	EventRunFinished    = "run_finished"
+	EventBudgetExceeded = "budget_exceeded"
+)
+
+// defaultTimeout bounds a single delivery when the notifier sets no timeout.
+const defaultTimeout = 30 * time.Second
+
+// Event is the payload sent to notifiers.
+type Event struct {
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo"`
+	RunID  string    `json:"run_id"`
+	TaskID string    `json:"task_id,omitempty"`
+	Title  string    `json:"title,omitempty"`
+	Status string    `json:"status,omitempty"`
+	Commit string    `json:"commit,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	Usage  Usage     `json:"usage"`
+}
+
+// Usage is the backend usage of the run so far.
+type Usage struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// Send delivers evt to every notifier subscribed to it. Each delivery runs even if
+// an earlier one failed; the failures are returned joined together. Cancellation of
+// ctx does not abort deliveries, so a run that is interrupted still reports it.
+func Send(ctx context.Context, notifiers []config.Notifier, evt Event) error {
+	if evt.Time.IsZero() {
+		evt.Time = time.Now().UTC()
+	}
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	var errs []error
+	for _, n := range notifiers {
+		if len(n.Events) > 0 && !slices.Contains(n.Events, evt.Event) {
+			continue
+		}
+		timeout := defaultTimeout
+		if n.TimeoutSeconds > 0 {
+			timeout = time.Duration(n.TimeoutSeconds) * time.Second
+		}
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		if n.Webhook != "" {
+			if err := postWebhook(sendCtx, n, payload); err != nil {
+				errs = append(errs, fmt.Errorf("webhook %s: %w", n.Webhook, err))
+			}
+		}
+		if n.Command != "" {
+			if err := runCommand(sendCtx, n, evt, payload); err != nil {
+				errs = append(errs, fmt.Errorf("command %q: %w", n.Command, err))
+			}
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// postWebhook POSTs payload to the notifier's webhook URL.
+func postWebhook(ctx context.Context, n config.Notifier, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "turbine")
+	for k, v := range n.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// runCommand runs the notifier's shell command in the repository with the event
+// as TURBINE_* environment variables and the JSON payload on stdin.
+func runCommand(ctx context.Context, n config.Notifier, evt Event, payload []byte) error {
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", n.Command)
+	cmd.Dir = evt.Repo
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), Env(evt)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := bytes.TrimSpace(out); len(msg) > 0 {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// Env returns evt as TURBINE_* environment variables.
+func Env(evt Event) []string {
+	return []string{
+		"TURBINE_EVENT=" + evt.Event,
+		"TURBINE_REPO=" + evt.Repo,
+		"TURBINE_RUN_ID=" + evt.RunID,
+		"TURBINE_TASK_ID=" + evt.TaskID,
+		"TURBINE_TASK_TITLE=" + evt.Title,
+		"TURBINE_STATUS=" + evt.Status,
+		"TURBINE_COMMIT=" + evt.Commit,
+		"TURBINE_ERROR=" + evt.Error,
+		"TURBINE_INPUT_TOKENS=" + strconv.Itoa(evt.Usage.InputTokens),
+		"TURBINE_OUTPUT_TOKENS=" + strconv.Itoa(evt.Usage.OutputTokens),
+		"TURBINE_COST_USD=" + strconv.FormatFloat(evt.Usage.CostUSD, 'f', 4, 64),
+	}
+}
@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/turbine/internal/config"
+)
+
+func TestSend_Webhook(t *testing.T) {
+	t.Setenv("NOTIFY_TOKEN", "secret")
+
+	var mu sync.Mutex
+	var got []Event
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		var evt Event
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&evt))
+		mu.Lock()
+		got = append(got, evt)
+		auth = req.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	notifiers := []config.Notifier{{
+		Webhook: srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer ${NOTIFY_TOKEN}"},
+		Events:  []string{EventTaskDone, EventRunFinished},
+	}}
+
+	ctx := context.Background()
+	require.NoError(t, Send(ctx, notifiers, Event{Event: EventTaskDone, RunID: "run-1", TaskID: "T1", Commit: "abc123", Usage: Usage{InputTokens: 10, CostUSD: 0.5}}))
+	require.NoError(t, Send(ctx, notifiers, Event{Event: EventTaskFailed, RunID: "run-1", TaskID: "T2"}), "unsubscribed events are skipped")
+
+	require.Len(t, got, 1)
+	assert.Equal(t, EventTaskDone, got[0].Event)
+	assert.Equal(t, "T1", got[0].TaskID)
+	assert.Equal(t, "abc123", got[0].Commit)
+	assert.Equal(t, 10, got[0].Usage.InputTokens)
+	assert.False(t, got[0].Time.IsZero())
+	assert.Equal(t, "Bearer secret", auth)
+}
+
+func TestSend_Command(t *testing.T) {
+	repo := t.TempDir()
+	notifiers := []config.Notifier{{Command: `printf '%s %s\n' "$TURBINE_EVENT" "$TURBINE_TASK_ID" > env.txt && cat > payload.json`}}
+
+	err := Send(context.Background(), notifiers, Event{Event: EventTaskFailed, Repo: repo, RunID: "run-1", TaskID: "T1", Error: "boom"})
+	require.NoError(t, err)
+
+	env, err := os.ReadFile(filepath.Join(repo, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "task_failed T1\n", string(env))
+
+	payload, err := os.ReadFile(filepath.Join(repo, "payload.json"))
+	require.NoError(t, err)
+	var evt Event
+	require.NoError(t, json.Unmarshal(payload, &evt))
+	assert.Equal(t, "boom", evt.Error)
+	assert.Equal(t, "run-1", evt.RunID)
+}
+
+func TestSend_Failures(t *testing.T) {
+	var delivered int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		delivered++
+		if strings.HasSuffix(req.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	notifiers := []config.Notifier{
+		{Webhook: srv.URL + "/fail"},
+		{Command: "echo nope >&2; exit 3"},
+		{Webhook: srv.URL + "/ok"},
+	}
+	err := Send(context.Background(), notifiers, Event{Event: EventRunFinished, Repo: t.TempDir()})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "500")
+	assert.Contains(t, err.Error(), "nope")
+	assert.Equal(t, 2, delivered, "later notifiers still run after a failure")
+}
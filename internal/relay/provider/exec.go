@@ -0,0 +1,279 @@
+package relayprovider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+)
+
+// Output formats supported by exec backends.
+const (
+	FormatText   = "text"
+	FormatNDJSON = "ndjson"
+)
+
+// maxStderr bounds the stderr kept for error messages.
+const maxStderr = 4 << 10
+
+// ExecProvider drives an arbitrary headless agent CLI described by an exec
+// backend in the config.
+type ExecProvider struct {
+	name    string
+	command string
+	args    []string
+	cfg     config.Exec
+}
+
+// NewExec returns a provider for the exec backend named name.
+func NewExec(name string, b config.Backend) (*ExecProvider, error) {
+	if b.Command == "" {
+		return nil, fmt.Errorf("backend %s: command is required for type exec", name)
+	}
+	switch b.Exec.Output.Format {
+	case "", FormatText, FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("backend %s: unknown output format %q (expected: text, ndjson)", name, b.Exec.Output.Format)
+	}
+	return &ExecProvider{name: name, command: b.Command, args: b.Args, cfg: b.Exec}, nil
+}
+
+// Name returns the backend's configured name.
+func (p *ExecProvider) Name() string { return p.name }
+
+// Run starts a new session.
+func (p *ExecProvider) Run(ctx context.Context, params relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	return p.run(ctx, "", params, events)
+}
+
+// Resume continues sessionID using the continue template.
+func (p *ExecProvider) Resume(ctx context.Context, sessionID string, params relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	if len(p.cfg.Continue) == 0 {
+		return fmt.Errorf("backend %s does not support resuming sessions (set exec.continue)", p.name)
+	}
+	return p.run(ctx, sessionID, params, events)
+}
+
+func (p *ExecProvider) run(ctx context.Context, sessionID string, params relay.RunParams, events chan<- relay.Event) error {
+	cmd := exec.CommandContext(ctx, p.command, p.Args(sessionID, params)...)
+	cmd.Dir = params.WorkingDir
+	if len(p.cfg.Prompt) == 0 {
+		cmd.Stdin = strings.NewReader(params.Prompt)
+	}
+	var stderr tailBuffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("backend %s: %w", p.name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("backend %s: start %s: %w", p.name, p.command, err)
+	}
+
+	parseErr := p.parse(stdout, events)
+	// Drain what the parser left so the process is not blocked on a full pipe.
+	_, _ = io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+
+	if waitErr != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("backend %s: %w: %s", p.name, waitErr, msg)
+		}
+		return fmt.Errorf("backend %s: %w", p.name, waitErr)
+	}
+	if parseErr != nil {
+		return fmt.Errorf("backend %s: read output: %w", p.name, parseErr)
+	}
+	return nil
+}
+
+// Args returns the command-line arguments for a run: the configured args, then
+// the continue, model, variant, workdir and prompt templates whose values are set.
+func (p *ExecProvider) Args(sessionID string, params relay.RunParams) []string {
+	vars := strings.NewReplacer(
+		"{prompt}", params.Prompt,
+		"{model}", params.Model,
+		"{variant}", params.Variant,
+		"{workdir}", params.WorkingDir,
+		"{session}", sessionID,
+	)
+	args := append([]string{}, p.args...)
+	add := func(value string, template []string) {
+		if value == "" {
+			return
+		}
+		for _, arg := range template {
+			args = append(args, vars.Replace(arg))
+		}
+	}
+	add(sessionID, p.cfg.Continue)
+	add(params.Model, p.cfg.Model)
+	add(params.Variant, p.cfg.Variant)
+	add(params.WorkingDir, p.cfg.WorkDir)
+	add(params.Prompt, p.cfg.Prompt)
+	return args
+}
+
+// parse converts the CLI's stdout into events.
+func (p *ExecProvider) parse(r io.Reader, events chan<- relay.Event) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var evt relay.Event
+		if p.cfg.Output.Format == FormatNDJSON {
+			evt = parseNDJSONLine(line, p.cfg.Output)
+		} else {
+			evt = relay.Event{Kind: relay.EventKindText, Text: line}
+		}
+		evt.Timestamp = time.Now()
+		events <- evt
+	}
+	return scanner.Err()
+}
+
+// parseNDJSONLine maps one JSON line to an event. Lines that are not JSON
+// objects are reported as agent text.
+func parseNDJSONLine(line string, out config.ExecOutput) relay.Event {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		return relay.Event{Kind: relay.EventKindText, Text: line}
+	}
+
+	evt := relay.Event{
+		Kind:      relay.EventKind(lookupString(doc, orDefault(out.Kind, "type"))),
+		Text:      lookupString(doc, orDefault(out.Text, "text")),
+		SessionID: lookupString(doc, orDefault(out.Session, "session_id")),
+	}
+	evt.Error = lookupError(doc, orDefault(out.Error, "error"), evt.Text)
+	if evt.Kind == "" {
+		evt.Kind = relay.EventKindText
+	}
+
+	in, hasIn := lookupNumber(doc, orDefault(out.InputTokens, "usage.input_tokens"))
+	outTokens, hasOut := lookupNumber(doc, orDefault(out.OutputTokens, "usage.output_tokens"))
+	cost, hasCost := lookupNumber(doc, orDefault(out.CostUSD, "cost_usd"))
+	if hasIn || hasOut || hasCost {
+		evt.Usage = &relay.Usage{InputTokens: int(in), OutputTokens: int(outTokens), CostUSD: cost}
+	}
+	return evt
+}
+
+// lookup returns the value at a dotted path in doc.
+func lookup(doc map[string]any, path string) (any, bool) {
+	var cur any = doc
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func lookupString(doc map[string]any, path string) string {
+	v, ok := lookup(doc, path)
+	if !ok || v == nil {
+		return ""
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		// Structured errors commonly carry their text under "message".
+		if msg, ok := v["message"].(string); ok {
+			return msg
+		}
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// lookupError is lookupString for error fields. Values that say there is no
+// error, such as false, 0, {} or [], yield "" rather than their JSON text. An
+// error flag set to true yields text, the event's message, if there is one.
+func lookupError(doc map[string]any, path, text string) string {
+	v, _ := lookup(doc, path)
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return ""
+		}
+		if text != "" {
+			return text
+		}
+	case float64:
+		if v == 0 {
+			return ""
+		}
+	case map[string]any:
+		if len(v) == 0 {
+			return ""
+		}
+	case []any:
+		if len(v) == 0 {
+			return ""
+		}
+	}
+	return lookupString(doc, path)
+}
+
+func lookupNumber(doc map[string]any, path string) (float64, bool) {
+	v, ok := lookup(doc, path)
+	if !ok {
+		return 0, false
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// tailBuffer keeps the last maxStderr bytes written to it.
+type tailBuffer struct {
+	buf bytes.Buffer
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	t.buf.Write(p)
+	if extra := t.buf.Len() - maxStderr; extra > 0 {
+		t.buf.Next(extra)
+	}
+	return n, nil
+}
+
+func (t *tailBuffer) String() string { return t.buf.String() }
+
+var _ relay.Provider = (*ExecProvider)(nil)
@@ -0,0 +1,161 @@
+package relayprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+)
+
+// collect runs fn with an events channel and returns the events it sent.
+func collect(t *testing.T, fn func(chan<- relay.Event) error) ([]relay.Event, error) {
+	t.Helper()
+	events := make(chan relay.Event)
+	var got []relay.Event
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for evt := range events {
+			got = append(got, evt)
+		}
+	}()
+	err := fn(events)
+	<-done
+	return got, err
+}
+
+func TestExecProvider_Args(t *testing.T) {
+	p, err := NewExec("mycli", config.Backend{
+		Type:    "exec",
+		Command: "mycli",
+		Args:    []string{"--headless"},
+		Exec: config.Exec{
+			Prompt:   []string{"-p", "{prompt}"},
+			Model:    []string{"--model={model}"},
+			Variant:  []string{"--effort", "{variant}"},
+			WorkDir:  []string{"--cwd", "{workdir}"},
+			Continue: []string{"--resume", "{session}"},
+		},
+	})
+	require.NoError(t, err)
+
+	params := relay.RunParams{Prompt: "do it", Model: "m1", WorkingDir: "/repo"}
+	assert.Equal(t, []string{"--headless", "--model=m1", "--cwd", "/repo", "-p", "do it"}, p.Args("", params))
+	assert.Equal(t, []string{"--headless", "--resume", "s-1", "--model=m1", "--cwd", "/repo", "-p", "do it"}, p.Args("s-1", params))
+}
+
+func TestExecProvider_RunText(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewExec("sh", config.Backend{
+		Command: "/bin/sh",
+		Args:    []string{"-c", `echo "prompt: $(cat)"; echo; echo "cwd: $(pwd)"`},
+	})
+	require.NoError(t, err)
+
+	got, err := collect(t, func(events chan<- relay.Event) error {
+		return p.Run(context.Background(), relay.RunParams{Prompt: "hello", WorkingDir: dir}, events)
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 2, "blank lines are skipped")
+	assert.Equal(t, relay.EventKindText, got[0].Kind)
+	assert.Equal(t, "prompt: hello", got[0].Text)
+	assert.Contains(t, got[1].Text, "cwd: ")
+	assert.Equal(t, "sh", p.Name())
+}
+
+func TestExecProvider_RunNDJSON(t *testing.T) {
+	script := `
+echo '{"type":"init","session_id":"sess-1"}'
+echo 'not json'
+echo '{"type":"message","content":{"text":"working on '"$1"'"}}'
+echo '{"type":"result","usage":{"in":10,"out":"5"},"cost":0.02}'
+echo '{"type":"error","error":{"message":"rate limited"}}'
+echo '{"type":"a","error":false}'
+echo '{"type":"b","error":0}'
+echo '{"type":"c","error":{}}'
+echo '{"type":"d","error":[]}'
+echo '{"type":"e","error":503}'
+echo '{"type":"f","error":true,"content":{"text":"Invalid API key"}}'
+echo '{"type":"g","error":true}'
+`
+	p, err := NewExec("nd", config.Backend{
+		Command: "/bin/sh",
+		Args:    []string{"-c", script, "sh"},
+		Exec: config.Exec{
+			Prompt: []string{"{prompt}"},
+			Output: config.ExecOutput{
+				Format:       FormatNDJSON,
+				Text:         "content.text",
+				InputTokens:  "usage.in",
+				OutputTokens: "usage.out",
+				CostUSD:      "cost",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	got, err := collect(t, func(events chan<- relay.Event) error {
+		return p.Run(context.Background(), relay.RunParams{Prompt: "T1"}, events)
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 12)
+
+	assert.Equal(t, relay.EventKind("init"), got[0].Kind)
+	assert.Equal(t, "sess-1", got[0].SessionID)
+	assert.Nil(t, got[0].Usage)
+
+	assert.Equal(t, relay.EventKindText, got[1].Kind)
+	assert.Equal(t, "not json", got[1].Text)
+
+	assert.Equal(t, "working on T1", got[2].Text)
+
+	require.NotNil(t, got[3].Usage)
+	assert.Equal(t, 10, got[3].Usage.InputTokens)
+	assert.Equal(t, 5, got[3].Usage.OutputTokens)
+	assert.InDelta(t, 0.02, got[3].Usage.CostUSD, 1e-9)
+
+	assert.Equal(t, "rate limited", got[4].Error)
+	for _, evt := range got[5:9] {
+		assert.Empty(t, evt.Error, "%s: values meaning no error are not errors", evt.Kind)
+	}
+	assert.Equal(t, "503", got[9].Error)
+	assert.Equal(t, "Invalid API key", got[10].Error, "an error flag takes the event's text")
+	assert.Equal(t, "true", got[11].Error)
+}
+
+func TestExecProvider_Errors(t *testing.T) {
+	p, err := NewExec("fails", config.Backend{Command: "/bin/sh", Args: []string{"-c", "echo partial; echo 'quota exhausted' >&2; exit 2"}})
+	require.NoError(t, err)
+	got, err := collect(t, func(events chan<- relay.Event) error {
+		return p.Run(context.Background(), relay.RunParams{}, events)
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "quota exhausted")
+	assert.Len(t, got, 1)
+
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return p.Resume(context.Background(), "s-1", relay.RunParams{}, events)
+	})
+	assert.ErrorContains(t, err, "does not support resuming")
+
+	_, err = NewExec("x", config.Backend{})
+	assert.ErrorContains(t, err, "command is required")
+	_, err = NewExec("x", config.Backend{Command: "x", Exec: config.Exec{Output: config.ExecOutput{Format: "xml"}}})
+	assert.ErrorContains(t, err, "unknown output format")
+}
+
+func TestNew(t *testing.T) {
+	p, err := New("mine", config.Backend{Type: "exec", Command: "agent"})
+	require.NoError(t, err)
+	assert.Equal(t, "mine", p.Name())
+
+	p, err = New("claude", config.Backend{Command: "claude"})
+	require.NoError(t, err)
+	assert.Equal(t, "claude", p.Name())
+
+	_, err = New("other", config.Backend{Command: "other"})
+	assert.ErrorContains(t, err, `unsupported backend type "other"`)
+}
@@ -0,0 +1,257 @@
+package relayprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/tasks"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario scripts the responses of a fake backend. Plans answer planning
+// calls and strokes answer task execution calls, each in order; once a list is
+// used up, planning reports that no work remains and strokes change nothing.
+type Scenario struct {
+	Plans   []tasks.Task `yaml:"plans"`   // Written to .turbine/task.yaml, one per planning call
+	Strokes []Response   `yaml:"strokes"` // One per execution stroke across the whole run
+	Explore *Response    `yaml:"explore"` // Every planning exploration call
+	Agents  *Response    `yaml:"agents"`  // Every AGENTS.md generation call
+}
+
+// Response is what the fake backend does for one call.
+type Response struct {
+	Text    string            `yaml:"text"`    // Shorthand for a single text event
+	Events  []FakeEvent       `yaml:"events"`  // Events emitted in order
+	Files   map[string]string `yaml:"files"`   // Files written, relative to the working directory
+	Delete  []string          `yaml:"delete"`  // Files removed, relative to the working directory
+	Usage   *FakeUsage        `yaml:"usage"`   // Usage reported at the end of the call
+	Session string            `yaml:"session"` // Session ID reported (default fake-N)
+	Error   string            `yaml:"error"`   // The call fails with this error after emitting events
+}
+
+// FakeEvent is a scripted backend event.
+type FakeEvent struct {
+	Kind  string `yaml:"kind"`
+	Text  string `yaml:"text"`
+	Error string `yaml:"error"`
+}
+
+// FakeUsage is scripted token usage.
+type FakeUsage struct {
+	InputTokens  int     `yaml:"input_tokens"`
+	OutputTokens int     `yaml:"output_tokens"`
+	CostUSD      float64 `yaml:"cost_usd"`
+}
+
+// Phases of a call, recognized by the role that opens the prompt.
+const (
+	PhaseExplore = "explore"
+	PhasePlan    = "plan"
+	PhaseAgents  = "agents"
+	PhaseStroke  = "stroke"
+)
+
+// phasePrefixes maps the opening of each role prompt to its phase. Calls that
+// match none are execution strokes.
+var phasePrefixes = []struct {
+	prefix string
+	phase  string
+}{
+	{"You are a codebase analyst.", PhaseExplore},
+	{"You are a just-in-time task planner.", PhasePlan},
+	{"You are an AGENTS.md generator.", PhaseAgents},
+}
+
+// Phase classifies a prompt sent by Turbine.
+func Phase(prompt string) string {
+	trimmed := strings.TrimSpace(prompt)
+	for _, p := range phasePrefixes {
+		if strings.HasPrefix(trimmed, p.prefix) {
+			return p.phase
+		}
+	}
+	return PhaseStroke
+}
+
+// FakeProvider plays a Scenario. It is deterministic and never calls out, so it
+// can drive full runs offline in tests and demos.
+type FakeProvider struct {
+	name     string
+	scenario Scenario
+
+	mu       sync.Mutex
+	plans    int
+	strokes  int
+	sessions int
+	// planned is the task written by the latest planning call; fix-up calls rewrite it.
+	planned *tasks.Task
+}
+
+// NewFake returns a fake provider named name playing scenario.
+func NewFake(name string, scenario Scenario) *FakeProvider {
+	return &FakeProvider{name: name, scenario: scenario}
+}
+
+// LoadScenario reads a scenario file.
+func LoadScenario(path string) (Scenario, error) {
+	var s Scenario
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, fmt.Errorf("read scenario: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Name returns the backend's configured name.
+func (p *FakeProvider) Name() string { return p.name }
+
+// Strokes returns the number of execution strokes played so far.
+func (p *FakeProvider) Strokes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.strokes
+}
+
+// Run plays the next response for the prompt's phase in a new session.
+func (p *FakeProvider) Run(ctx context.Context, params relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	p.mu.Lock()
+	p.sessions++
+	session := fmt.Sprintf("fake-%d", p.sessions)
+	p.mu.Unlock()
+	return p.play(ctx, session, params, events)
+}
+
+// Resume plays the next response in the given session.
+func (p *FakeProvider) Resume(ctx context.Context, sessionID string, params relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	return p.play(ctx, sessionID, params, events)
+}
+
+func (p *FakeProvider) play(ctx context.Context, session string, params relay.RunParams, events chan<- relay.Event) error {
+	resp, err := p.next(params)
+	if err != nil {
+		return err
+	}
+	if resp.Session != "" {
+		session = resp.Session
+	}
+
+	send := func(evt relay.Event) error {
+		evt.Timestamp = time.Now()
+		evt.SessionID = session
+		select {
+		case events <- evt:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if resp.Text != "" {
+		if err := send(relay.Event{Kind: relay.EventKindText, Text: resp.Text}); err != nil {
+			return err
+		}
+	}
+	for _, e := range resp.Events {
+		kind := relay.EventKind(e.Kind)
+		if kind == "" {
+			kind = relay.EventKindText
+		}
+		if err := send(relay.Event{Kind: kind, Text: e.Text, Error: e.Error}); err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range resp.Delete {
+		if err := os.Remove(filepath.Join(params.WorkingDir, rel)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("fake backend: %w", err)
+		}
+	}
+	for rel, content := range resp.Files {
+		path := filepath.Join(params.WorkingDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("fake backend: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("fake backend: %w", err)
+		}
+	}
+
+	if resp.Usage != nil {
+		usage := &relay.Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens, CostUSD: resp.Usage.CostUSD}
+		if err := send(relay.Event{Kind: "usage", Usage: usage}); err != nil {
+			return err
+		}
+	}
+
+	if resp.Error != "" {
+		return fmt.Errorf("fake backend: %s", resp.Error)
+	}
+	return nil
+}
+
+// next picks the response for a call and, for planning calls, writes the task.
+func (p *FakeProvider) next(params relay.RunParams) (Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch Phase(params.Prompt) {
+	case PhaseExplore:
+		return deref(p.scenario.Explore), nil
+	case PhaseAgents:
+		return deref(p.scenario.Agents), nil
+	case PhasePlan:
+		// The first planning prompt of a session plans the next task; follow-up
+		// fix prompts rewrite the same task.
+		if p.planned == nil || !strings.Contains(params.Prompt, "Fix Invalid") {
+			task := tasks.Task{
+				ID:          "DONE",
+				Title:       "No remaining work",
+				Status:      tasks.StatusDone,
+				Description: "All PRD requirements are satisfied.",
+			}
+			if p.plans < len(p.scenario.Plans) {
+				task = p.scenario.Plans[p.plans]
+				if task.Status == "" {
+					task.Status = tasks.StatusTodo
+				}
+			}
+			p.plans++
+			p.planned = &task
+		}
+		file := &tasks.TaskFile{Version: tasks.SchemaVersion, Task: *p.planned}
+		path := filepath.Join(params.WorkingDir, ".turbine", "task.yaml")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return Response{}, fmt.Errorf("fake backend: %w", err)
+		}
+		if err := file.Save(path); err != nil {
+			return Response{}, fmt.Errorf("fake backend: %w", err)
+		}
+		return Response{Text: fmt.Sprintf("Planned %s", p.planned.ID)}, nil
+	default:
+		p.strokes++
+		if p.strokes <= len(p.scenario.Strokes) {
+			return p.scenario.Strokes[p.strokes-1], nil
+		}
+		return Response{}, nil
+	}
+}
+
+func deref(r *Response) Response {
+	if r == nil {
+		return Response{}
+	}
+	return *r
+}
+
+var _ relay.Provider = (*FakeProvider)(nil)
@@ -0,0 +1,87 @@
+package relayprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+const plannerPrompt = "You are a just-in-time task planner. Plan the next task."
+
+func TestFakeProvider(t *testing.T) {
+	dir := t.TempDir()
+	scenarioPath := filepath.Join(dir, "scenario.yaml")
+	require.NoError(t, os.WriteFile(scenarioPath, []byte(`
+plans:
+  - id: T-001
+    title: First
+    description: Do it.
+    commit_message: "feat: first"
+strokes:
+  - text: working
+    files: {src/a.txt: "a"}
+    usage: {input_tokens: 3, output_tokens: 4}
+  - error: crashed
+`), 0644))
+	scenario, err := LoadScenario(scenarioPath)
+	require.NoError(t, err)
+	p := NewFake("fake", scenario)
+	ctx := context.Background()
+	taskPath := filepath.Join(dir, ".turbine", "task.yaml")
+
+	run := func(prompt string) ([]relay.Event, error) {
+		return collect(t, func(events chan<- relay.Event) error {
+			return p.Run(ctx, relay.RunParams{Prompt: prompt, WorkingDir: dir}, events)
+		})
+	}
+
+	_, err = run(plannerPrompt)
+	require.NoError(t, err)
+	planned, err := tasks.LoadTaskFile(taskPath)
+	require.NoError(t, err)
+	assert.Equal(t, "T-001", planned.Task.ID)
+	assert.Equal(t, tasks.StatusTodo, planned.Task.Status)
+
+	require.NoError(t, os.WriteFile(taskPath, []byte("broken"), 0644))
+	_, err = run(plannerPrompt + "\n## Task: Fix Invalid .turbine/task.yaml")
+	require.NoError(t, err)
+	planned, err = tasks.LoadTaskFile(taskPath)
+	require.NoError(t, err)
+	assert.Equal(t, "T-001", planned.Task.ID, "fix prompts rewrite the current plan")
+
+	got, err := run("implement T-001")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "working", got[0].Text)
+	assert.Equal(t, "fake-3", got[0].SessionID)
+	require.NotNil(t, got[1].Usage)
+	assert.Equal(t, 4, got[1].Usage.OutputTokens)
+	data, err := os.ReadFile(filepath.Join(dir, "src", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+
+	_, err = run("retry T-001")
+	assert.ErrorContains(t, err, "crashed")
+
+	got, err = run("retry again")
+	require.NoError(t, err, "strokes beyond the scenario change nothing")
+	assert.Empty(t, got)
+	assert.Equal(t, 3, p.Strokes())
+
+	_, err = run(plannerPrompt)
+	require.NoError(t, err)
+	planned, err = tasks.LoadTaskFile(taskPath)
+	require.NoError(t, err)
+	assert.Equal(t, tasks.StatusDone, planned.Task.Status, "planning past the scenario reports no remaining work")
+}
+
+func TestPhase(t *testing.T) {
+	assert.Equal(t, PhasePlan, Phase("\n"+plannerPrompt))
+	assert.Equal(t, PhaseStroke, Phase("You are a coding agent working within the Turbine harness."))
+}
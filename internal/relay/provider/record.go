@@ -0,0 +1,403 @@
+package relayprovider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/gitx"
+	filestore "github.com/yarlson/turbine/internal/relay/store"
+)
+
+// Recording line types. A recorded call is one NDJSON file holding a call line,
+// one event line per emitted event, and a result line.
+const (
+	recordCall   = "call"
+	recordEvent  = "event"
+	recordResult = "result"
+)
+
+// snapshotExclude keeps Turbine's own run data out of recorded file diffs.
+var snapshotExclude = []string{".turbine/runs", ".turbine/state"}
+
+// RecordLine is one line of a recorded call.
+type RecordLine struct {
+	Type string `json:"type"`
+
+	// call
+	Seq        int       `json:"seq,omitempty"`
+	Backend    string    `json:"backend,omitempty"`
+	Phase      string    `json:"phase,omitempty"`
+	ResumeID   string    `json:"resume_id,omitempty"`
+	Prompt     string    `json:"prompt,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Variant    string    `json:"variant,omitempty"`
+	WorkingDir string    `json:"working_dir,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+
+	// event
+	Event *RecordedEvent `json:"event,omitempty"`
+
+	// result
+	Error       string `json:"error,omitempty"`
+	Patch       string `json:"patch,omitempty"`
+	RecordError string `json:"record_error,omitempty"`
+	DurationMS  int64  `json:"duration_ms,omitempty"`
+}
+
+// RecordedEvent is a backend event as recorded.
+type RecordedEvent struct {
+	Kind      string       `json:"kind"`
+	SessionID string       `json:"session_id,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Usage     *relay.Usage `json:"usage,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// RecordingDir returns the directory holding the recording of runID.
+func RecordingDir(repoRoot, runID string) string {
+	return filepath.Join(repoRoot, ".turbine", "runs", runID, "raw")
+}
+
+// Recorder wraps a provider and records every call: the prompt and parameters,
+// each event, the returned error, and the diff of the working tree the call made.
+// Calls are written as raw NDJSON payloads of the run, one file per call, under
+// .turbine/runs/<run-id>/raw/.
+type Recorder struct {
+	relay.Provider
+	repoRoot string
+	runID    string
+	store    *filestore.FileStore
+	seq      *callSeq
+}
+
+// callSeq numbers the calls of a run across all of its recorders.
+type callSeq struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (s *callSeq) next() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n++
+	return s.n
+}
+
+// NewRecorder returns a provider recording p's calls for runID. A resumed run
+// continues numbering after the calls already recorded.
+func NewRecorder(p relay.Provider, repoRoot, runID string) *Recorder {
+	seq := &callSeq{}
+	if files, err := recordingFiles(RecordingDir(repoRoot, runID)); err == nil {
+		seq.n = len(files)
+	}
+	return &Recorder{Provider: p, repoRoot: repoRoot, runID: runID, store: filestore.New(repoRoot), seq: seq}
+}
+
+// Wrap returns a recorder for another backend of the same run, sharing the
+// call numbering.
+func (r *Recorder) Wrap(p relay.Provider) *Recorder {
+	return &Recorder{Provider: p, repoRoot: r.repoRoot, runID: r.runID, store: r.store, seq: r.seq}
+}
+
+// Run records a new-session call.
+func (r *Recorder) Run(ctx context.Context, params relay.RunParams, events chan<- relay.Event) error {
+	return r.record(ctx, "", params, events, func(inner chan<- relay.Event) error {
+		return r.Provider.Run(ctx, params, inner)
+	})
+}
+
+// Resume records a call continuing sessionID.
+func (r *Recorder) Resume(ctx context.Context, sessionID string, params relay.RunParams, events chan<- relay.Event) error {
+	return r.record(ctx, sessionID, params, events, func(inner chan<- relay.Event) error {
+		return r.Provider.Resume(ctx, sessionID, params, inner)
+	})
+}
+
+func (r *Recorder) record(ctx context.Context, resumeID string, params relay.RunParams, events chan<- relay.Event, call func(chan<- relay.Event) error) error {
+	defer close(events)
+
+	seq := r.seq.next()
+	phase := Phase(params.Prompt)
+	stepID := fmt.Sprintf("%04d-%s", seq, phase)
+	dir := params.WorkingDir
+	if dir == "" {
+		dir = r.repoRoot
+	}
+
+	var recordErrs []string
+	write := func(line RecordLine) {
+		data, err := json.Marshal(line)
+		if err == nil {
+			err = r.store.StreamRawPayload(ctx, r.runID, stepID, data)
+		}
+		if err != nil {
+			recordErrs = append(recordErrs, err.Error())
+		}
+	}
+
+	start := time.Now()
+	write(RecordLine{
+		Type:       recordCall,
+		Seq:        seq,
+		Backend:    r.Name(),
+		Phase:      phase,
+		ResumeID:   resumeID,
+		Prompt:     params.Prompt,
+		Model:      params.Model,
+		Variant:    params.Variant,
+		WorkingDir: params.WorkingDir,
+		StartedAt:  start.UTC(),
+	})
+
+	before, snapErr := gitx.SnapshotTree(ctx, dir, snapshotExclude...)
+
+	inner := make(chan relay.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for evt := range inner {
+			write(RecordLine{Type: recordEvent, Event: &RecordedEvent{
+				Kind:      string(evt.Kind),
+				SessionID: evt.SessionID,
+				Text:      evt.Text,
+				Usage:     evt.Usage,
+				Error:     evt.Error,
+			}})
+			events <- evt
+		}
+	}()
+	err := call(inner)
+	<-done
+
+	result := RecordLine{Type: recordResult, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if snapErr == nil {
+		var after string
+		if after, snapErr = gitx.SnapshotTree(ctx, dir, snapshotExclude...); snapErr == nil {
+			result.Patch, snapErr = gitx.DiffTrees(ctx, dir, before, after)
+		}
+	}
+	if snapErr != nil {
+		recordErrs = append(recordErrs, snapErr.Error())
+	}
+	result.RecordError = strings.Join(recordErrs, "; ")
+	write(result)
+
+	return err
+}
+
+// recordedCall is a call loaded from a recording.
+type recordedCall struct {
+	call   RecordLine
+	events []RecordedEvent
+	result RecordLine
+}
+
+// ReplayProvider reproduces a recorded run: each call plays the next recorded
+// call's events, applies its file diff and returns its error.
+type ReplayProvider struct {
+	name     string
+	repoRoot string
+	log      *replayLog
+}
+
+// replayLog is the recorded calls of a run, shared by the views of all its backends.
+type replayLog struct {
+	calls []recordedCall
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewReplay loads the recording of runID.
+func NewReplay(repoRoot, runID string) (*ReplayProvider, error) {
+	dir := RecordingDir(repoRoot, runID)
+	files, err := recordingFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read recording %s: %w", runID, err)
+	}
+	if len(files) == 0 {
+		archive := filepath.Join(repoRoot, ".turbine", "runs", runID+".tar.gz")
+		if _, err := os.Stat(archive); err == nil {
+			return nil, fmt.Errorf("run %s is compressed; extract it with tar -xzf %s -C %s to replay it", runID, archive, filepath.Dir(archive))
+		}
+		return nil, fmt.Errorf("no recording found for run %s (record with --record)", runID)
+	}
+
+	log := &replayLog{}
+	for i, path := range files {
+		call, err := loadRecordedCall(path)
+		if errors.Is(err, errIncompleteCall) && i < len(files)-1 {
+			// The run was interrupted during this call and then resumed; the
+			// calls after it are what the resumed run did.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.calls = append(log.calls, call)
+	}
+	return &ReplayProvider{name: log.calls[0].call.Backend, repoRoot: repoRoot, log: log}, nil
+}
+
+// Name returns the recorded backend's name.
+func (p *ReplayProvider) Name() string { return p.name }
+
+// As returns a view of the same recording for the backend named name. Calls
+// through any view replay the run in order.
+func (p *ReplayProvider) As(name string) *ReplayProvider {
+	return &ReplayProvider{name: name, repoRoot: p.repoRoot, log: p.log}
+}
+
+// Remaining returns the number of recorded calls not yet replayed.
+func (p *ReplayProvider) Remaining() int {
+	p.log.mu.Lock()
+	defer p.log.mu.Unlock()
+	return len(p.log.calls) - p.log.next
+}
+
+// Run replays the next recorded call.
+func (p *ReplayProvider) Run(ctx context.Context, params relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	return p.replay(ctx, "", params, events)
+}
+
+// Resume replays the next recorded call, which must also have been a resume.
+func (p *ReplayProvider) Resume(ctx context.Context, sessionID string, params relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	return p.replay(ctx, sessionID, params, events)
+}
+
+func (p *ReplayProvider) replay(ctx context.Context, sessionID string, params relay.RunParams, events chan<- relay.Event) error {
+	log := p.log
+	log.mu.Lock()
+	if log.next >= len(log.calls) {
+		log.mu.Unlock()
+		return fmt.Errorf("replay diverged: recording has only %d calls", len(log.calls))
+	}
+	rec := log.calls[log.next]
+	log.next++
+	log.mu.Unlock()
+
+	phase := Phase(params.Prompt)
+	if rec.call.Phase != phase || rec.call.Backend != p.name || (rec.call.ResumeID == "") != (sessionID == "") {
+		return fmt.Errorf("replay diverged at call %d: recorded %s, got %s", rec.call.Seq,
+			describeCall(rec.call.Backend, rec.call.Phase, rec.call.ResumeID), describeCall(p.name, phase, sessionID))
+	}
+
+	for _, e := range rec.events {
+		evt := relay.Event{
+			Kind:      relay.EventKind(e.Kind),
+			Timestamp: time.Now(),
+			SessionID: e.SessionID,
+			Text:      e.Text,
+			Usage:     e.Usage,
+			Error:     e.Error,
+		}
+		select {
+		case events <- evt:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	dir := params.WorkingDir
+	if dir == "" {
+		dir = p.repoRoot
+	}
+	if err := gitx.ApplyPatch(ctx, dir, rec.result.Patch); err != nil {
+		return fmt.Errorf("replay diverged at call %d: %w", rec.call.Seq, err)
+	}
+
+	if rec.result.Error != "" {
+		return errors.New(rec.result.Error)
+	}
+	return nil
+}
+
+func describeCall(backend, phase, sessionID string) string {
+	if sessionID != "" {
+		return fmt.Sprintf("a %s call to %s resuming a session", phase, backend)
+	}
+	return fmt.Sprintf("a %s call to %s", phase, backend)
+}
+
+// recordingName matches the files the Recorder writes, leaving other raw
+// payloads of the run alone.
+var recordingName = regexp.MustCompile(`^\d{4}-[a-z]+\.ndjson$`)
+
+// recordingFiles returns the recorded call files in dir in call order.
+func recordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && recordingName.MatchString(e.Name()) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// errIncompleteCall marks a recorded call without a result line.
+var errIncompleteCall = errors.New("the run was interrupted during this call")
+
+func loadRecordedCall(path string) (recordedCall, error) {
+	var rec recordedCall
+	f, err := os.Open(path)
+	if err != nil {
+		return rec, fmt.Errorf("open recording: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	var sawCall, sawResult bool
+	for scanner.Scan() {
+		var line RecordLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return rec, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+		}
+		switch line.Type {
+		case recordCall:
+			rec.call, sawCall = line, true
+		case recordEvent:
+			if line.Event != nil {
+				rec.events = append(rec.events, *line.Event)
+			}
+		case recordResult:
+			rec.result, sawResult = line, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return rec, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	if !sawCall || !sawResult {
+		return rec, fmt.Errorf("incomplete recording %s (%w)", filepath.Base(path), errIncompleteCall)
+	}
+	return rec, nil
+}
+
+var (
+	_ relay.Provider = (*Recorder)(nil)
+	_ relay.Provider = (*ReplayProvider)(nil)
+)
@@ -0,0 +1,169 @@
+package relayprovider
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("readme\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".turbine/runs/\n.turbine/state/\n"), 0644))
+	return dir
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	src := initRepo(t)
+	fake := NewFake("fake", Scenario{Strokes: []Response{
+		{Text: "writing", Files: map[string]string{"src/a.txt": "a\n"}, Delete: []string{"README.md"}, Usage: &FakeUsage{InputTokens: 5, OutputTokens: 6}},
+		{Text: "crashing", Error: "overloaded"},
+	}})
+	rec := NewRecorder(fake, src, "run-1")
+	assert.Equal(t, "fake", rec.Name())
+
+	recorded, err := collect(t, func(events chan<- relay.Event) error {
+		return rec.Run(ctx, relay.RunParams{Prompt: "implement T-001", Model: "slow", WorkingDir: src}, events)
+	})
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return rec.Resume(ctx, "fake-1", relay.RunParams{Prompt: "continue", WorkingDir: src}, events)
+	})
+	require.EqualError(t, err, "fake backend: overloaded")
+
+	files, err := recordingFiles(RecordingDir(src, "run-1"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "0001-stroke.ndjson", filepath.Base(files[0]))
+	assert.Equal(t, 2, NewRecorder(fake, src, "run-1").seq.n, "a resumed run continues numbering")
+
+	// Replay into a fresh copy of the starting tree.
+	dst := initRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(dst, ".turbine", "runs"), 0755))
+	require.NoError(t, os.Rename(filepath.Join(src, ".turbine", "runs", "run-1"), filepath.Join(dst, ".turbine", "runs", "run-1")))
+	replay, err := NewReplay(dst, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", replay.Name())
+
+	replayed, err := collect(t, func(events chan<- relay.Event) error {
+		return replay.Run(ctx, relay.RunParams{Prompt: "implement T-001", WorkingDir: dst}, events)
+	})
+	require.NoError(t, err)
+	require.Len(t, replayed, len(recorded))
+	for i := range recorded {
+		assert.Equal(t, recorded[i].Kind, replayed[i].Kind)
+		assert.Equal(t, recorded[i].Text, replayed[i].Text)
+		assert.Equal(t, recorded[i].SessionID, replayed[i].SessionID)
+		assert.Equal(t, recorded[i].Usage, replayed[i].Usage)
+	}
+	content, err := os.ReadFile(filepath.Join(dst, "src", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(content))
+	assert.NoFileExists(t, filepath.Join(dst, "README.md"))
+
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return replay.Resume(ctx, "fake-1", relay.RunParams{Prompt: "continue", WorkingDir: dst}, events)
+	})
+	require.EqualError(t, err, "fake backend: overloaded", "recorded errors are returned")
+	assert.Equal(t, 0, replay.Remaining())
+
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return replay.Run(ctx, relay.RunParams{Prompt: "more", WorkingDir: dst}, events)
+	})
+	require.EqualError(t, err, "replay diverged: recording has only 2 calls")
+}
+
+func TestReplay_Diverged(t *testing.T) {
+	ctx := context.Background()
+	dir := initRepo(t)
+	rec := NewRecorder(NewFake("fake", Scenario{}), dir, "run-1")
+	_, err := collect(t, func(events chan<- relay.Event) error {
+		return rec.Run(ctx, relay.RunParams{Prompt: "implement T-001", WorkingDir: dir}, events)
+	})
+	require.NoError(t, err)
+
+	replay, err := NewReplay(dir, "run-1")
+	require.NoError(t, err)
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return replay.Run(ctx, relay.RunParams{Prompt: plannerPrompt, WorkingDir: dir}, events)
+	})
+	require.EqualError(t, err, "replay diverged at call 1: recorded a stroke call to fake, got a plan call to fake")
+
+	_, err = NewReplay(dir, "missing")
+	require.EqualError(t, err, "no recording found for run missing (record with --record)")
+
+	require.NoError(t, os.WriteFile(filepath.Join(RecordingDir(dir, "run-1"), "0002-stroke.ndjson"), []byte(`{"type":"call","seq":2}`+"\n"), 0644))
+	_, err = NewReplay(dir, "run-1")
+	require.ErrorContains(t, err, "incomplete recording 0002-stroke.ndjson")
+
+	// A resumed run recorded more calls after the interrupted one, which is skipped.
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return NewRecorder(NewFake("fake", Scenario{}), dir, "run-1").Run(ctx, relay.RunParams{Prompt: "implement T-001", WorkingDir: dir}, events)
+	})
+	require.NoError(t, err)
+	replay, err = NewReplay(dir, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, replay.Remaining())
+
+	archive := filepath.Join(dir, ".turbine", "runs", "old.tar.gz")
+	require.NoError(t, os.WriteFile(archive, nil, 0644))
+	_, err = NewReplay(dir, "old")
+	require.EqualError(t, err, "run old is compressed; extract it with tar -xzf "+archive+" -C "+filepath.Dir(archive)+" to replay it")
+}
+
+func TestRecordAndReplay_MultipleBackends(t *testing.T) {
+	ctx := context.Background()
+	dir := initRepo(t)
+	primary := NewRecorder(NewFake("primary", Scenario{}), dir, "run-1")
+	backup := primary.Wrap(NewFake("backup", Scenario{Strokes: []Response{{Text: "from backup"}}}))
+	for _, p := range []relay.Provider{primary, backup} {
+		_, err := collect(t, func(events chan<- relay.Event) error {
+			return p.Run(ctx, relay.RunParams{Prompt: "implement", WorkingDir: dir}, events)
+		})
+		require.NoError(t, err)
+	}
+	files, err := recordingFiles(RecordingDir(dir, "run-1"))
+	require.NoError(t, err)
+	require.Len(t, files, 2, "wrapped recorders share the call numbering")
+
+	replay, err := NewReplay(dir, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", replay.Name())
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return replay.Run(ctx, relay.RunParams{Prompt: "implement", WorkingDir: dir}, events)
+	})
+	require.NoError(t, err)
+	_, err = collect(t, func(events chan<- relay.Event) error {
+		return replay.Run(ctx, relay.RunParams{Prompt: "implement", WorkingDir: dir}, events)
+	})
+	require.EqualError(t, err, "replay diverged at call 2: recorded a stroke call to backup, got a stroke call to primary")
+
+	replay, err = NewReplay(dir, "run-1")
+	require.NoError(t, err)
+	for _, p := range []relay.Provider{replay, replay.As("backup")} {
+		_, err := collect(t, func(events chan<- relay.Event) error {
+			return p.Run(ctx, relay.RunParams{Prompt: "implement", WorkingDir: dir}, events)
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, replay.Remaining())
+}
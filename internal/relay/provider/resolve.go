@@ -28,21 +28,7 @@ func Resolve(cfg *config.Config, overrides config.Overrides, defaultModel string
 		return nil, "", "", fmt.Errorf("unknown backend: %s", effCfg.Backend)
 	}
 
-	provCfg := provider.Config{
-		Executable: bCfg.Command,
-		Args:       bCfg.Args,
-	}
-
-	var p relay.Provider
-	var err error
-	switch effCfg.Backend {
-	case "opencode":
-		p, err = opencode.New(provCfg)
-	case "claude":
-		p, err = claude.New(provCfg)
-	default:
-		return nil, "", "", fmt.Errorf("unsupported backend: %s", effCfg.Backend)
-	}
+	p, err := New(effCfg.Backend, bCfg)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -62,21 +48,7 @@ func ResolveWithModels(cfg *config.Config, overrides config.Overrides) (relay.Pr
 		return nil, config.Model{}, config.Model{}, fmt.Errorf("unknown backend: %s", backendName)
 	}
 
-	provCfg := provider.Config{
-		Executable: bCfg.Command,
-		Args:       bCfg.Args,
-	}
-
-	var p relay.Provider
-	var err error
-	switch backendName {
-	case "opencode":
-		p, err = opencode.New(provCfg)
-	case "claude":
-		p, err = claude.New(provCfg)
-	default:
-		return nil, config.Model{}, config.Model{}, fmt.Errorf("unsupported backend: %s", backendName)
-	}
+	p, err := New(backendName, bCfg)
 	if err != nil {
 		return nil, config.Model{}, config.Model{}, err
 	}
@@ -95,3 +67,37 @@ func ResolveWithModels(cfg *config.Config, overrides config.Overrides) (relay.Pr
 
 	return p, fast, slow, nil
 }
+
+// New returns the provider for the backend named name. The backend's type
+// selects the implementation and defaults to its name.
+func New(name string, b config.Backend) (relay.Provider, error) {
+	typ := b.Type
+	if typ == "" {
+		typ = name
+	}
+
+	provCfg := provider.Config{
+		Executable: b.Command,
+		Args:       b.Args,
+	}
+
+	switch typ {
+	case "opencode":
+		return opencode.New(provCfg)
+	case "claude":
+		return claude.New(provCfg)
+	case "exec":
+		return NewExec(name, b)
+	case "fake":
+		if b.Scenario == "" {
+			return nil, fmt.Errorf("backend %s: scenario is required for type fake", name)
+		}
+		scenario, err := LoadScenario(b.Scenario)
+		if err != nil {
+			return nil, fmt.Errorf("backend %s: %w", name, err)
+		}
+		return NewFake(name, scenario), nil
+	default:
+		return nil, fmt.Errorf("unsupported backend type %q for backend %s (expected: opencode, claude, exec, fake)", typ, name)
+	}
+}
@@ -0,0 +1,37 @@
+package relaystore
+
+import (
+	relaystore "github.com/yarlson/relay/store"
+	"github.com/yarlson/turbine/internal/schema"
+)
+
+// SchemaVersion is the store layout version written by this build, recorded in
+// every index entry. Versions 1 and 2 kept all workflows in relay.json and all
+// step summaries in steps.json (version 1 as bare maps keyed by ID); they are
+// imported into the log layout on first use.
+const SchemaVersion = 3
+
+// legacySchemaVersion is the last version of relay.json and steps.json.
+const legacySchemaVersion = 2
+
+type workflowStatesFile struct {
+	SchemaVersion int                                  `json:"schema_version"`
+	Workflows     map[string]*relaystore.WorkflowState `json:"workflows"`
+}
+
+type stepSummariesFile struct {
+	SchemaVersion int                                `json:"schema_version"`
+	Steps         map[string]*relaystore.StepSummary `json:"steps"`
+}
+
+var (
+	workflowStatesSchema = schema.New("relay.json", "schema_version", legacySchemaVersion, 1).Register(1, wrapUnder("workflows"))
+	stepSummariesSchema  = schema.New("steps.json", "schema_version", legacySchemaVersion, 1).Register(1, wrapUnder("steps"))
+)
+
+// wrapUnder moves a version 1 bare map under key.
+func wrapUnder(key string) schema.Migration {
+	return func(doc map[string]any) (map[string]any, error) {
+		return map[string]any{key: doc}, nil
+	}
+}
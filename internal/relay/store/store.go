@@ -1,19 +1,53 @@
 package relaystore
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	relaystore "github.com/yarlson/relay/store"
+	"github.com/yarlson/turbine/internal/atomicfile"
+	"github.com/yarlson/turbine/internal/schema"
 )
 
+const (
+	// maxWorkflowLogSize is the size at which a workflow log is compacted down to
+	// its latest record. It bounds the work done by LoadWorkflowState.
+	maxWorkflowLogSize = 64 << 10
+	// minStepsLogGrowth is how much the steps log may grow past its last compacted
+	// size (and at least double) before it is compacted again.
+	minStepsLogGrowth = 256 << 10
+)
+
+// FileStore persists relay workflow state under .turbine/state/relay as append-only
+// logs: one log of WorkflowState snapshots per workflow, a shared log of step
+// summaries, and an index log listing workflows. Saves append a single record, so
+// their cost does not depend on how much history has accumulated.
 type FileStore struct {
 	repoRoot string
 	mu       sync.Mutex
+	migrated bool
+}
+
+// WorkflowEntry is an index record describing a stored workflow.
+type WorkflowEntry struct {
+	SchemaVersion int       `json:"schema_version"`
+	WorkflowID    string    `json:"workflow_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type stepsMeta struct {
+	CompactedSize int64 `json:"compacted_size"`
 }
 
 func New(repoRoot string) *FileStore {
@@ -29,13 +63,10 @@ func (s *FileStore) SaveWorkflowState(ctx context.Context, state *relaystore.Wor
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	states, err := s.loadWorkflowStates()
-	if err != nil {
+	if err := s.migrateLegacy(); err != nil {
 		return err
 	}
-	states[state.WorkflowID] = state
-
-	return s.writeJSONFile(s.statePath(), states)
+	return s.saveWorkflowState(state)
 }
 
 func (s *FileStore) LoadWorkflowState(ctx context.Context, workflowID string) (*relaystore.WorkflowState, error) {
@@ -44,15 +75,26 @@ func (s *FileStore) LoadWorkflowState(ctx context.Context, workflowID string) (*
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	states, err := s.loadWorkflowStates()
-	if err != nil {
+	if err := s.migrateLegacy(); err != nil {
 		return nil, err
 	}
-	state, ok := states[workflowID]
-	if !ok {
+
+	data, err := lastRecord(s.workflowLogPath(workflowID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, relaystore.ErrNotFound
+		}
+		return nil, fmt.Errorf("read workflow log: %w", err)
+	}
+	if data == nil {
 		return nil, relaystore.ErrNotFound
 	}
-	return state, nil
+
+	var state relaystore.WorkflowState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal workflow state: %w", err)
+	}
+	return &state, nil
 }
 
 func (s *FileStore) SaveStepSummary(ctx context.Context, summary *relaystore.StepSummary) error {
@@ -64,13 +106,37 @@ func (s *FileStore) SaveStepSummary(ctx context.Context, summary *relaystore.Ste
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	summaries, err := s.loadStepSummaries()
-	if err != nil {
+	if err := s.migrateLegacy(); err != nil {
 		return err
 	}
-	summaries[summary.StepID] = summary
+	return s.saveStepSummary(summary)
+}
+
+// Workflows lists the stored workflows in the order they were first saved.
+func (s *FileStore) Workflows() ([]WorkflowEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.migrateLegacy(); err != nil {
+		return nil, err
+	}
 
-	return s.writeJSONFile(s.stepsPath(), summaries)
+	var entries []WorkflowEntry
+	err := eachRecord(s.indexPath(), func(data []byte) error {
+		var entry WorkflowEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil // torn record
+		}
+		if entry.SchemaVersion > SchemaVersion {
+			return &schema.NewerVersionError{File: "relay/index.log", Version: entry.SchemaVersion, Supported: SchemaVersion}
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return entries, nil
 }
 
 func (s *FileStore) AppendEvent(ctx context.Context, workflowID string, event *relaystore.Event) error {
@@ -128,10 +194,161 @@ func (s *FileStore) StreamRawPayload(ctx context.Context, workflowID, stepID str
 	return nil
 }
 
+func (s *FileStore) saveWorkflowState(state *relaystore.WorkflowState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal workflow state: %w", err)
+	}
+
+	path := s.workflowLogPath(state.WorkflowID)
+	_, statErr := os.Stat(path)
+	isNew := errors.Is(statErr, fs.ErrNotExist)
+
+	size, err := appendRecord(path, data)
+	if err != nil {
+		return fmt.Errorf("append workflow state: %w", err)
+	}
+
+	if isNew {
+		entry, err := json.Marshal(WorkflowEntry{SchemaVersion: SchemaVersion, WorkflowID: state.WorkflowID, CreatedAt: time.Now().UTC()})
+		if err != nil {
+			return fmt.Errorf("marshal index entry: %w", err)
+		}
+		if _, err := appendRecord(s.indexPath(), entry); err != nil {
+			return fmt.Errorf("append index entry: %w", err)
+		}
+	}
+
+	if size > maxWorkflowLogSize {
+		if err := atomicfile.WriteFile(path, append(data, '\n'), 0644, "", nil); err != nil {
+			return fmt.Errorf("compact workflow log: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) saveStepSummary(summary *relaystore.StepSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshal step summary: %w", err)
+	}
+
+	size, err := appendRecord(s.stepsLogPath(), data)
+	if err != nil {
+		return fmt.Errorf("append step summary: %w", err)
+	}
+
+	var meta stepsMeta
+	if err := readJSON(s.stepsMetaPath(), &meta); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read steps metadata: %w", err)
+	}
+	if size > 2*meta.CompactedSize+minStepsLogGrowth {
+		return s.compactSteps()
+	}
+	return nil
+}
+
+// compactSteps rewrites the steps log keeping only the latest summary per step.
+// Compaction happens once the log has at least doubled, so its cost is amortized
+// over the saves in between.
+func (s *FileStore) compactSteps() error {
+	latest := map[string][]byte{}
+	var order []string
+	err := eachRecord(s.stepsLogPath(), func(data []byte) error {
+		var summary relaystore.StepSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return nil // torn record
+		}
+		if _, ok := latest[summary.StepID]; !ok {
+			order = append(order, summary.StepID)
+		}
+		latest[summary.StepID] = append([]byte(nil), data...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read steps log: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, id := range order {
+		buf.Write(latest[id])
+		buf.WriteByte('\n')
+	}
+	if err := atomicfile.WriteFile(s.stepsLogPath(), buf.Bytes(), 0644, "", nil); err != nil {
+		return fmt.Errorf("compact steps log: %w", err)
+	}
+
+	meta, err := json.Marshal(stepsMeta{CompactedSize: int64(buf.Len())})
+	if err != nil {
+		return fmt.Errorf("marshal steps metadata: %w", err)
+	}
+	if err := atomicfile.WriteFile(s.stepsMetaPath(), meta, 0644, "", nil); err != nil {
+		return fmt.Errorf("write steps metadata: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacy imports relay.json and steps.json written by older builds into the
+// log layout and removes them.
+func (s *FileStore) migrateLegacy() error {
+	if s.migrated {
+		return nil
+	}
+
+	states, err := s.loadWorkflowStates()
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		if err := s.saveWorkflowState(state); err != nil {
+			return err
+		}
+	}
+	summaries, err := s.loadStepSummaries()
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if err := s.saveStepSummary(summary); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range []string{s.statePath(), s.stepsPath()} {
+		if err := atomicfile.Remove(path, path+".bak"); err != nil {
+			return fmt.Errorf("remove legacy store file: %w", err)
+		}
+	}
+	s.migrated = true
+	return nil
+}
+
+func (s *FileStore) relayDir() string {
+	return filepath.Join(s.repoRoot, ".turbine", "state", "relay")
+}
+
+func (s *FileStore) workflowLogPath(workflowID string) string {
+	return filepath.Join(s.relayDir(), "workflows", url.PathEscape(workflowID)+".log")
+}
+
+func (s *FileStore) stepsLogPath() string {
+	return filepath.Join(s.relayDir(), "steps.log")
+}
+
+func (s *FileStore) stepsMetaPath() string {
+	return filepath.Join(s.relayDir(), "steps.meta.json")
+}
+
+func (s *FileStore) indexPath() string {
+	return filepath.Join(s.relayDir(), "index.log")
+}
+
+// statePath is the version 1-2 file holding every workflow state.
 func (s *FileStore) statePath() string {
 	return filepath.Join(s.repoRoot, ".turbine", "state", "relay.json")
 }
 
+// stepsPath is the version 1-2 file holding every step summary.
 func (s *FileStore) stepsPath() string {
 	return filepath.Join(s.repoRoot, ".turbine", "state", "steps.json")
 }
@@ -145,34 +362,58 @@ func (s *FileStore) rawPath(workflowID, stepID string) string {
 }
 
 func (s *FileStore) loadWorkflowStates() (map[string]*relaystore.WorkflowState, error) {
-	var states map[string]*relaystore.WorkflowState
-	if err := s.readJSONFile(s.statePath(), &states); err != nil {
+	var file workflowStatesFile
+	if err := s.readJSONFile(s.statePath(), workflowStatesSchema, &file); err != nil {
 		if os.IsNotExist(err) {
 			return map[string]*relaystore.WorkflowState{}, nil
 		}
 		return nil, err
 	}
-	if states == nil {
-		states = map[string]*relaystore.WorkflowState{}
+	if file.Workflows == nil {
+		file.Workflows = map[string]*relaystore.WorkflowState{}
 	}
-	return states, nil
+	return file.Workflows, nil
 }
 
 func (s *FileStore) loadStepSummaries() (map[string]*relaystore.StepSummary, error) {
-	var summaries map[string]*relaystore.StepSummary
-	if err := s.readJSONFile(s.stepsPath(), &summaries); err != nil {
+	var file stepSummariesFile
+	if err := s.readJSONFile(s.stepsPath(), stepSummariesSchema, &file); err != nil {
 		if os.IsNotExist(err) {
 			return map[string]*relaystore.StepSummary{}, nil
 		}
 		return nil, err
 	}
-	if summaries == nil {
-		summaries = map[string]*relaystore.StepSummary{}
+	if file.Steps == nil {
+		file.Steps = map[string]*relaystore.StepSummary{}
 	}
-	return summaries, nil
+	return file.Steps, nil
+}
+
+// readJSONFile migrates and decodes path, recovering from its backup copy if it is corrupt.
+func (s *FileStore) readJSONFile(path string, registry *schema.Registry, dest interface{}) error {
+	return atomicfile.ReadFile(path, path+".bak", func(data []byte) error {
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		doc, migrated, err := registry.Migrate(doc)
+		if err != nil {
+			var newer *schema.NewerVersionError
+			if errors.As(err, &newer) {
+				return atomicfile.Unrecoverable(err)
+			}
+			return err
+		}
+		if migrated {
+			if data, err = json.Marshal(doc); err != nil {
+				return err
+			}
+		}
+		return json.Unmarshal(data, dest)
+	})
 }
 
-func (s *FileStore) readJSONFile(path string, dest interface{}) error {
+func readJSON(path string, dest interface{}) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -180,16 +421,74 @@ func (s *FileStore) readJSONFile(path string, dest interface{}) error {
 	return json.Unmarshal(data, dest)
 }
 
-func (s *FileStore) writeJSONFile(path string, data interface{}) error {
+// appendRecord appends data as one line to path and returns the new file size.
+// A torn final line left by a crash is terminated first so it cannot swallow
+// the new record.
+func appendRecord(path string, data []byte) (int64, error) {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("create state dir: %w", err)
+		return 0, fmt.Errorf("create log dir: %w", err)
 	}
-	encoded, err := json.MarshalIndent(data, "", "  ")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
 	if err != nil {
-		return fmt.Errorf("marshal json: %w", err)
+		return 0, err
 	}
-	if err := os.WriteFile(path, encoded, 0644); err != nil {
-		return fmt.Errorf("write json file: %w", err)
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	record := make([]byte, 0, len(data)+2)
+	if size := info.Size(); size > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, size-1); err != nil {
+			return 0, err
+		}
+		if last[0] != '\n' {
+			record = append(record, '\n')
+		}
+	}
+	record = append(record, data...)
+	record = append(record, '\n')
+	if _, err := f.Write(record); err != nil {
+		return 0, err
+	}
+	return info.Size() + int64(len(record)), nil
+}
+
+// eachRecord calls fn with every non-empty line of path.
+func eachRecord(path string, fn func(data []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			if fnErr := fn(trimmed); fnErr != nil {
+				return fnErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// lastRecord returns the last line of path that is valid JSON, or nil if there is none.
+func lastRecord(path string) ([]byte, error) {
+	var last []byte
+	err := eachRecord(path, func(data []byte) error {
+		if json.Valid(data) {
+			last = append(last[:0], data...)
+		}
+		return nil
+	})
+	return last, err
 }
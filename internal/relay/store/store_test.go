@@ -0,0 +1,195 @@
+package relaystore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relaystore "github.com/yarlson/relay/store"
+)
+
+func TestFileStore_WorkflowStateRoundtrip(t *testing.T) {
+	ctx := context.Background()
+	s := New(t.TempDir())
+
+	_, err := s.LoadWorkflowState(ctx, "wf-1")
+	assert.ErrorIs(t, err, relaystore.ErrNotFound)
+
+	require.NoError(t, s.SaveWorkflowState(ctx, &relaystore.WorkflowState{WorkflowID: "wf-1"}))
+	require.NoError(t, s.SaveWorkflowState(ctx, &relaystore.WorkflowState{WorkflowID: "wf-2"}))
+	require.NoError(t, s.SaveWorkflowState(ctx, &relaystore.WorkflowState{WorkflowID: "wf-1"}))
+
+	state, err := s.LoadWorkflowState(ctx, "wf-1")
+	require.NoError(t, err)
+	assert.Equal(t, "wf-1", state.WorkflowID)
+
+	// A fresh store reads what an earlier one wrote.
+	state, err = New(s.repoRoot).LoadWorkflowState(ctx, "wf-2")
+	require.NoError(t, err)
+	assert.Equal(t, "wf-2", state.WorkflowID)
+
+	entries, err := s.Workflows()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "wf-1", entries[0].WorkflowID)
+	assert.Equal(t, "wf-2", entries[1].WorkflowID)
+	assert.Equal(t, SchemaVersion, entries[0].SchemaVersion)
+}
+
+func TestFileStore_TornRecord(t *testing.T) {
+	ctx := context.Background()
+	s := New(t.TempDir())
+
+	require.NoError(t, s.SaveWorkflowState(ctx, &relaystore.WorkflowState{WorkflowID: "wf-1"}))
+
+	// Simulate a crash in the middle of an append.
+	f, err := os.OpenFile(s.workflowLogPath("wf-1"), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"workflow_id": "wf-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	state, err := s.LoadWorkflowState(ctx, "wf-1")
+	require.NoError(t, err)
+	assert.Equal(t, "wf-1", state.WorkflowID)
+
+	require.NoError(t, s.SaveWorkflowState(ctx, &relaystore.WorkflowState{WorkflowID: "wf-1"}))
+	state, err = s.LoadWorkflowState(ctx, "wf-1")
+	require.NoError(t, err)
+	assert.Equal(t, "wf-1", state.WorkflowID)
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	ctx := context.Background()
+	s := New(t.TempDir())
+
+	for i := 0; i < 3000; i++ {
+		require.NoError(t, s.SaveWorkflowState(ctx, &relaystore.WorkflowState{WorkflowID: "wf-1"}))
+	}
+	info, err := os.Stat(s.workflowLogPath("wf-1"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(maxWorkflowLogSize))
+
+	state, err := s.LoadWorkflowState(ctx, "wf-1")
+	require.NoError(t, err)
+	assert.Equal(t, "wf-1", state.WorkflowID)
+
+	for i := 0; i < 20000; i++ {
+		require.NoError(t, s.SaveStepSummary(ctx, &relaystore.StepSummary{StepID: fmt.Sprintf("step-%d", i%10)}))
+	}
+	info, err = os.Stat(s.stepsLogPath())
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(2*minStepsLogGrowth))
+	assert.FileExists(t, s.stepsMetaPath())
+}
+
+func TestFileStore_MigratesLegacyFiles(t *testing.T) {
+	ctx := context.Background()
+
+	legacy := map[string]func() ([]byte, []byte, error){
+		"version 1": func() ([]byte, []byte, error) {
+			states, err := json.Marshal(map[string]*relaystore.WorkflowState{"wf-old": {WorkflowID: "wf-old"}})
+			if err != nil {
+				return nil, nil, err
+			}
+			steps, err := json.Marshal(map[string]*relaystore.StepSummary{"step-old": {StepID: "step-old"}})
+			return states, steps, err
+		},
+		"version 2": func() ([]byte, []byte, error) {
+			states, err := json.Marshal(workflowStatesFile{SchemaVersion: 2, Workflows: map[string]*relaystore.WorkflowState{"wf-old": {WorkflowID: "wf-old"}}})
+			if err != nil {
+				return nil, nil, err
+			}
+			steps, err := json.Marshal(stepSummariesFile{SchemaVersion: 2, Steps: map[string]*relaystore.StepSummary{"step-old": {StepID: "step-old"}}})
+			return states, steps, err
+		},
+	}
+
+	for name, build := range legacy {
+		t.Run(name, func(t *testing.T) {
+			s := New(t.TempDir())
+			states, steps, err := build()
+			require.NoError(t, err)
+			require.NoError(t, os.MkdirAll(filepath.Dir(s.statePath()), 0755))
+			require.NoError(t, os.WriteFile(s.statePath(), states, 0644))
+			require.NoError(t, os.WriteFile(s.stepsPath(), steps, 0644))
+
+			state, err := s.LoadWorkflowState(ctx, "wf-old")
+			require.NoError(t, err)
+			assert.Equal(t, "wf-old", state.WorkflowID)
+
+			assert.NoFileExists(t, s.statePath())
+			assert.NoFileExists(t, s.stepsPath())
+			data, err := os.ReadFile(s.stepsLogPath())
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "step-old")
+		})
+	}
+}
+
+func TestFileStore_NewerVersion(t *testing.T) {
+	ctx := context.Background()
+	s := New(t.TempDir())
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(s.statePath()), 0755))
+	require.NoError(t, os.WriteFile(s.statePath(), []byte(`{"schema_version": 3, "workflows": {}}`), 0644))
+
+	_, err := s.LoadWorkflowState(ctx, "wf-1")
+	assert.ErrorContains(t, err, "relay.json has schema version 3")
+}
+
+// BenchmarkFileStore_SaveWorkflowState shows that saves cost the same no matter
+// how many workflows and saves the store already holds.
+func BenchmarkFileStore_SaveWorkflowState(b *testing.B) {
+	ctx := context.Background()
+	for _, history := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("history=%d", history), func(b *testing.B) {
+			s := New(b.TempDir())
+			for i := 0; i < history; i++ {
+				state := &relaystore.WorkflowState{WorkflowID: fmt.Sprintf("wf-%d", i%(history/10))}
+				if err := s.SaveWorkflowState(ctx, state); err != nil {
+					b.Fatal(err)
+				}
+				if err := s.SaveStepSummary(ctx, &relaystore.StepSummary{StepID: fmt.Sprintf("step-%d", i)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			state := &relaystore.WorkflowState{WorkflowID: "wf-0"}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.SaveWorkflowState(ctx, state); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFileStore_SaveStepSummary shows the amortized cost of step summary
+// saves, compaction included, as the log grows.
+func BenchmarkFileStore_SaveStepSummary(b *testing.B) {
+	ctx := context.Background()
+	for _, history := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("history=%d", history), func(b *testing.B) {
+			s := New(b.TempDir())
+			for i := 0; i < history; i++ {
+				if err := s.SaveStepSummary(ctx, &relaystore.StepSummary{StepID: fmt.Sprintf("step-%d", i)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.SaveStepSummary(ctx, &relaystore.StepSummary{StepID: fmt.Sprintf("step-%d", i%history)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ const (
 	SubDirBackend = "backend"
 	SubDirVerify  = "verify"
 	SubDirGit     = "git"
+	SubDirHooks   = "hooks"
 )
 
 // Artifacts manages the directory layout and file persistence for a single run.
@@ -40,6 +41,7 @@ func NewArtifacts(repoRoot, runID string) (*Artifacts, error) {
 		filepath.Join(runRoot, SubDirBackend),
 		filepath.Join(runRoot, SubDirVerify),
 		filepath.Join(runRoot, SubDirGit),
+		filepath.Join(runRoot, SubDirHooks),
 	}
 
 	for _, dir := range subDirs {
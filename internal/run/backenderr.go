@@ -0,0 +1,80 @@
+package run
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrorClass is the kind of a backend failure.
+type ErrorClass string
+
+const (
+	ErrorRateLimited    ErrorClass = "rate_limited"
+	ErrorOverloaded     ErrorClass = "overloaded"
+	ErrorNetwork        ErrorClass = "network"
+	ErrorAuth           ErrorClass = "auth"
+	ErrorContextTooLong ErrorClass = "context_too_long"
+	ErrorCrash          ErrorClass = "crash"
+)
+
+// Transient reports whether retrying the same call later may succeed.
+func (c ErrorClass) Transient() bool {
+	return c == ErrorRateLimited || c == ErrorOverloaded || c == ErrorNetwork
+}
+
+// Fatal reports whether no stroke can succeed on the backend until the user acts.
+func (c ErrorClass) Fatal() bool {
+	return c == ErrorAuth
+}
+
+// errorPatterns maps backend error messages to classes, checked in order.
+var errorPatterns = []struct {
+	class   ErrorClass
+	pattern *regexp.Regexp
+}{
+	{ErrorAuth, regexp.MustCompile(`\b(401|403)\b|unauthori[sz]ed|forbidden|authentication|invalid (x-)?api[ _-]?key|api key .*(invalid|missing)|not logged in|/login|credentials`)},
+	{ErrorContextTooLong, regexp.MustCompile(`context[ _](length|window)|prompt is too long|too many tokens|maximum context|context_length_exceeded|input is too long`)},
+	{ErrorRateLimited, regexp.MustCompile(`\b429\b|rate[ _-]?limit|too many requests|quota|usage limit`)},
+	{ErrorOverloaded, regexp.MustCompile(`\b(500|502|503|504|529)\b|overloaded|service unavailable|bad gateway|gateway timeout|internal server error|capacity`)},
+	{ErrorNetwork, regexp.MustCompile(`connection (reset|refused|closed)|timed? ?out|timeout|no such host|network is unreachable|temporary failure|tls handshake|broken pipe|unexpected eof`)},
+}
+
+// ClassifyBackendError classifies a backend failure from its error message and
+// any error reported in its events. Unrecognized failures are crashes.
+func ClassifyBackendError(messages ...string) ErrorClass {
+	text := strings.ToLower(strings.Join(messages, "\n"))
+	for _, p := range errorPatterns {
+		if p.pattern.MatchString(text) {
+			return p.class
+		}
+	}
+	return ErrorCrash
+}
+
+// BackendError is a stroke that failed in the backend rather than in verification
+// or a hook.
+type BackendError struct {
+	Backend string
+	Class   ErrorClass
+	Err     error
+}
+
+func (e *BackendError) Error() string {
+	msg := fmt.Sprintf("backend failed (%s): %v", e.Class, e.Err)
+	if hint := e.Hint(); hint != "" {
+		msg += "; " + hint
+	}
+	return msg
+}
+
+func (e *BackendError) Unwrap() error { return e.Err }
+
+// Hint tells the user how to fix a fatal failure.
+func (e *BackendError) Hint() string {
+	switch e.Class {
+	case ErrorAuth:
+		return fmt.Sprintf("log in to the %s CLI or check its API key, then run turbine again to resume", e.Backend)
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+func TestClassifyBackendError(t *testing.T) {
+	tests := []struct {
+		messages []string
+		want     ErrorClass
+	}{
+		{[]string{"exit status 1: API Error: 429 Too Many Requests"}, ErrorRateLimited},
+		{[]string{"exit status 1", "Claude usage limit reached"}, ErrorRateLimited},
+		{[]string{`{"type":"overloaded_error","message":"Overloaded"}`}, ErrorOverloaded},
+		{[]string{"upstream returned 503 Service Unavailable"}, ErrorOverloaded},
+		{[]string{"read tcp: connection reset by peer"}, ErrorNetwork},
+		{[]string{"context deadline exceeded (Client.Timeout exceeded)"}, ErrorNetwork},
+		{[]string{"Invalid API key · Please run /login"}, ErrorAuth},
+		{[]string{"401 Unauthorized"}, ErrorAuth},
+		{[]string{"prompt is too long: 210000 tokens > 200000 maximum"}, ErrorContextTooLong},
+		{[]string{"exit status 139: segmentation fault"}, ErrorCrash},
+		{[]string{""}, ErrorCrash},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyBackendError(tt.messages...), tt.messages)
+	}
+}
+
+func TestBackendError(t *testing.T) {
+	err := &BackendError{Backend: "claude", Class: ErrorAuth, Err: errors.New("401 Unauthorized")}
+	assert.Equal(t, "backend failed (auth): 401 Unauthorized; log in to the claude CLI or check its API key, then run turbine again to resume", err.Error())
+	assert.True(t, err.Class.Fatal())
+
+	err = &BackendError{Backend: "claude", Class: ErrorOverloaded, Err: errors.New("overloaded")}
+	assert.Equal(t, "backend failed (overloaded): overloaded", err.Error())
+	assert.True(t, err.Class.Transient())
+}
+
+func TestRetryPolicy_BackendErrors(t *testing.T) {
+	ctx := context.Background()
+	newRunner := func(t *testing.T) *Runner {
+		return &Runner{
+			RepoRoot: setupTestRepo(t),
+			State:    &state.RunState{RunID: "test-run"},
+		}
+	}
+	backendErr := func(class ErrorClass) error {
+		return &BackendError{Backend: "mock", Class: class, Err: errors.New(string(class))}
+	}
+
+	t.Run("transient errors are retried without using strokes", func(t *testing.T) {
+		r := newRunner(t)
+		policy := &RetryPolicy{MaxStrokes: 2, MaxRotations: 1, Backoff: config.Backoff{Attempts: 3, Initial: time.Millisecond}}
+		calls := 0
+		err := policy.Execute(ctx, r, &tasks.Task{ID: "T1"}, func(context.Context) error {
+			calls++
+			if calls <= 3 {
+				return backendErr(ErrorRateLimited)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, 1, r.State.Stroke)
+	})
+
+	t.Run("exhausted retries use up the stroke", func(t *testing.T) {
+		r := newRunner(t)
+		policy := &RetryPolicy{MaxStrokes: 2, MaxRotations: 1, Backoff: config.Backoff{Attempts: 1, Initial: time.Millisecond}}
+		calls := 0
+		err := policy.Execute(ctx, r, &tasks.Task{ID: "T1"}, func(context.Context) error {
+			calls++
+			return backendErr(ErrorOverloaded)
+		})
+		require.EqualError(t, err, "T1 failed after 1 rotations")
+		assert.Equal(t, 4, calls, "each of the 2 strokes is tried twice")
+	})
+
+	t.Run("fatal errors abort at once", func(t *testing.T) {
+		r := newRunner(t)
+		policy := &RetryPolicy{MaxStrokes: 3, MaxRotations: 3}
+		calls := 0
+		err := policy.Execute(ctx, r, &tasks.Task{ID: "T1"}, func(context.Context) error {
+			calls++
+			return backendErr(ErrorAuth)
+		})
+		var be *BackendError
+		require.ErrorAs(t, err, &be)
+		assert.Equal(t, ErrorAuth, be.Class)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, 1, r.State.Stroke)
+	})
+
+	t.Run("fatal errors fail over when possible", func(t *testing.T) {
+		r := newRunner(t)
+		switched := false
+		policy := &RetryPolicy{MaxStrokes: 3, MaxRotations: 3, Failover: func(*BackendError) bool {
+			if switched {
+				return false
+			}
+			switched = true
+			return true
+		}}
+		calls := 0
+		err := policy.Execute(ctx, r, &tasks.Task{ID: "T1"}, func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return backendErr(ErrorAuth)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 1, r.State.Stroke, "the stroke is retried on the new backend")
+	})
+
+	t.Run("context too long drops the session", func(t *testing.T) {
+		r := newRunner(t)
+		policy := &RetryPolicy{MaxStrokes: 2, MaxRotations: 1}
+		calls := 0
+		err := policy.Execute(ctx, r, &tasks.Task{ID: "T1"}, func(context.Context) error {
+			calls++
+			if calls == 1 {
+				r.State.BackendSessionID = "s1"
+				return backendErr(ErrorContextTooLong)
+			}
+			assert.Empty(t, r.State.BackendSessionID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, r.State.Stroke)
+	})
+
+	t.Run("backoff stops on cancel", func(t *testing.T) {
+		r := newRunner(t)
+		ctx, cancel := context.WithCancel(ctx)
+		policy := &RetryPolicy{MaxStrokes: 1, MaxRotations: 1, Backoff: config.Backoff{Initial: time.Hour}}
+		err := policy.Execute(ctx, r, &tasks.Task{ID: "T1"}, func(context.Context) error {
+			cancel()
+			return backendErr(ErrorNetwork)
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestExecuteTask_AuthFailureKeepsTask(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"true"},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(repoDir, TaskRelPath)))
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config:   config.Defaults{Retry: config.Retry{Strokes: 3, Rotations: 3}},
+	}
+	mock := &mockProvider{runFunc: func(_ context.Context, _ relay.RunParams, events chan<- relay.Event) error {
+		events <- relay.Event{Kind: "error", Error: "Invalid API key · Please run /login"}
+		return errors.New("exit status 1")
+	}}
+
+	err := r.ExecuteTask(ctx, mock, "sonnet", "")
+	require.ErrorContains(t, err, "backend failed (auth)")
+	require.ErrorContains(t, err, "log in to the mock CLI")
+	assert.Equal(t, tasks.StatusTodo, taskFile.Task.Status, "the task is kept for resume")
+
+	saved, exists, err := state.Load(repoDir)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, "T1", saved.ActiveTaskID)
+	assert.Equal(t, 1, saved.Stroke)
+}
+
+func TestExecuteTask_ContextTooLongStartsNewSession(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"true"},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(repoDir, TaskRelPath)))
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config:   config.Defaults{Retry: config.Retry{Strokes: 2, Rotations: 1}},
+	}
+	mock := &sessionMock{runErrors: []string{"prompt is too long: 210000 tokens > 200000 maximum"}}
+	require.NoError(t, r.ExecuteTask(ctx, mock, "sonnet", ""))
+
+	assert.Empty(t, mock.resumed, "the session that was too long is not continued")
+	assert.Equal(t, 2, mock.runs)
+	assert.Equal(t, 2, r.State.Stroke)
+}
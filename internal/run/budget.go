@@ -0,0 +1,63 @@
+package run
+
+import (
+	"context"
+	"fmt"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/notify"
+)
+
+// BudgetExceededError stops a run whose backend usage reached defaults.budget.
+// The run state is kept, so raising the budget and running again resumes it.
+type BudgetExceededError struct {
+	Tokens  int
+	CostUSD float64
+	Budget  config.Budget
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("budget exceeded: used %d tokens and %.4f USD (limits: max_tokens %d, max_cost_usd %.4f); raise defaults.budget and run again to resume",
+		e.Tokens, e.CostUSD, e.Budget.MaxTokens, e.Budget.MaxCostUSD)
+}
+
+// addUsage accumulates the usage reported by evt into the run state.
+func (r *Runner) addUsage(evt relay.Event) {
+	if evt.Usage == nil {
+		return
+	}
+	r.State.InputTokens += evt.Usage.InputTokens
+	r.State.OutputTokens += evt.Usage.OutputTokens
+	r.State.CostUSD += evt.Usage.CostUSD
+}
+
+// checkBudget returns a *BudgetExceededError, after notifying, once the run's
+// usage reaches the configured budget.
+func (r *Runner) checkBudget(ctx context.Context) error {
+	tokens := r.State.InputTokens + r.State.OutputTokens
+	if !r.Config.Budget.Exceeded(tokens, r.State.CostUSD) {
+		return nil
+	}
+	err := &BudgetExceededError{Tokens: tokens, CostUSD: r.State.CostUSD, Budget: r.Config.Budget}
+	r.notify(ctx, notify.Event{Event: notify.EventBudgetExceeded, TaskID: r.State.ActiveTaskID, Error: err.Error()})
+	return err
+}
+
+// notify sends evt to the configured notifiers. Failures are logged as warnings
+// and never fail the run.
+func (r *Runner) notify(ctx context.Context, evt notify.Event) {
+	if len(r.Config.Notify) == 0 {
+		return
+	}
+	evt.Repo = r.RepoRoot
+	evt.RunID = r.State.RunID
+	evt.Usage = notify.Usage{
+		InputTokens:  r.State.InputTokens,
+		OutputTokens: r.State.OutputTokens,
+		CostUSD:      r.State.CostUSD,
+	}
+	if err := notify.Send(ctx, r.Config.Notify, evt); err != nil {
+		r.warnf("Notification %s failed: %v", evt.Event, err)
+	}
+}
@@ -0,0 +1,139 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/notify"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+func TestRunner_BudgetExceeded(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	tasksDir := filepath.Join(repoDir, ".turbine")
+	require.NoError(t, os.MkdirAll(tasksDir, 0755))
+
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			Verify:        []string{"false"},
+			CommitMessage: "feat: task 1",
+		},
+	}
+	taskPath := filepath.Join(tasksDir, "task.yaml")
+	require.NoError(t, taskFile.Save(taskPath))
+
+	var mu sync.Mutex
+	var notified []notify.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var evt notify.Event
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&evt))
+		mu.Lock()
+		notified = append(notified, evt)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config: config.Defaults{
+			Retry:  config.Retry{Strokes: 3, Rotations: 1},
+			Budget: config.Budget{MaxTokens: 100},
+			Notify: []config.Notifier{
+				{Webhook: srv.URL},
+				{Webhook: "http://127.0.0.1:1/unreachable"},
+			},
+		},
+		Output: Output{Quiet: true},
+	}
+
+	strokes := 0
+	mock := &mockProvider{runFunc: func(_ context.Context, _ relay.RunParams, events chan<- relay.Event) error {
+		strokes++
+		events <- relay.Event{Kind: "step_finish", Usage: &relay.Usage{InputTokens: 80, OutputTokens: 40, CostUSD: 0.25}}
+		return nil
+	}}
+
+	err := r.Run(ctx, mock, Models{Fast: config.Model{Name: "fast"}})
+	var budgetErr *BudgetExceededError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, 120, budgetErr.Tokens)
+	assert.Equal(t, 1, strokes, "no stroke starts once the budget is spent")
+
+	saved, exists, err := state.Load(repoDir)
+	require.NoError(t, err)
+	require.True(t, exists, "state is kept so the run can resume")
+	assert.Equal(t, "T1", saved.ActiveTaskID)
+	assert.Equal(t, 80, saved.InputTokens)
+	assert.Equal(t, 40, saved.OutputTokens)
+	assert.InDelta(t, 0.25, saved.CostUSD, 1e-9)
+
+	loaded, err := tasks.LoadTaskFile(taskPath)
+	require.NoError(t, err)
+	assert.Equal(t, tasks.StatusTodo, loaded.Task.Status, "budget stops are not task failures")
+
+	require.Len(t, notified, 2, "the unreachable notifier does not break the run")
+	assert.Equal(t, notify.EventBudgetExceeded, notified[0].Event)
+	assert.Equal(t, "T1", notified[0].TaskID)
+	assert.Equal(t, 120, notified[0].Usage.InputTokens+notified[0].Usage.OutputTokens)
+	assert.Equal(t, notify.EventRunFinished, notified[1].Event)
+	assert.Equal(t, StatusFailed, notified[1].Status)
+	assert.Equal(t, repoDir, notified[1].Repo)
+}
+
+func TestRunner_BudgetCountsPlanning(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	prdPath := filepath.Join(repoDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0644))
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		State:    &state.RunState{RunID: "test-run"},
+		PRDPath:  prdPath,
+		Config: config.Defaults{
+			Retry:  config.Retry{Strokes: 3, Rotations: 1},
+			Budget: config.Budget{MaxTokens: 100},
+		},
+		Output: Output{Quiet: true},
+	}
+
+	// Calls 0 and 1 explore and plan; any later call would be a stroke.
+	calls := 0
+	mock := &mockProvider{runFunc: func(_ context.Context, params relay.RunParams, events chan<- relay.Event) error {
+		calls++
+		events <- relay.Event{Kind: "step_finish", Usage: &relay.Usage{InputTokens: 40, OutputTokens: 20}}
+		if calls == 2 {
+			tf := &tasks.TaskFile{Version: 1, Task: tasks.Task{
+				ID: "T1", Title: "Task 1", Status: tasks.StatusTodo, Description: "Description 1", CommitMessage: "feat: task 1",
+			}}
+			return tf.Save(filepath.Join(params.WorkingDir, TaskRelPath))
+		}
+		return nil
+	}}
+
+	err := r.Run(ctx, mock, Models{Fast: config.Model{Name: "fast"}})
+	var budgetErr *BudgetExceededError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, 120, budgetErr.Tokens, "planning usage counts toward the budget")
+	assert.Equal(t, 2, calls, "no stroke starts once planning spent the budget")
+}
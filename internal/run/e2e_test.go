@@ -0,0 +1,143 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	relayprovider "github.com/yarlson/turbine/internal/relay/provider"
+	"github.com/yarlson/turbine/internal/state"
+)
+
+const e2eScenario = `
+plans:
+  - id: T-001
+    title: Create greeting
+    description: Write hello.txt.
+    verify: ["test -f hello.txt"]
+    commit_message: "feat: add greeting"
+  - id: T-002
+    title: Greet the world
+    description: Extend hello.txt.
+    verify: ["grep -q world hello.txt"]
+    commit_message: "feat: greet the world"
+strokes:
+  # T-001, rotation 1: a broken attempt, then a backend crash
+  - text: Editing README
+    files: {README.md: "broken\n"}
+    usage: {input_tokens: 100, output_tokens: 10, cost_usd: 0.01}
+  - text: Thinking
+    error: agent exited unexpectedly
+  # T-001, rotation 2
+  - events:
+      - {kind: tool_use, text: Write hello.txt}
+    files: {hello.txt: "hello\n"}
+    usage: {input_tokens: 200, output_tokens: 20, cost_usd: 0.02}
+  # T-002
+  - files: {hello.txt: "hello world\n"}
+`
+
+// setupE2ERepo returns a test repo with a committed PRD, made the working directory.
+func setupE2ERepo(t *testing.T) string {
+	t.Helper()
+	repoDir := setupTestRepo(t)
+	t.Chdir(repoDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, PRDRelPath), []byte("# Greeter\n"), 0644))
+	gitOutput(t, repoDir, "add", PRDRelPath)
+	gitOutput(t, repoDir, "commit", "-m", "add prd", "--no-gpg-sign")
+	return repoDir
+}
+
+func newE2EBackend(t *testing.T) relay.Provider {
+	t.Helper()
+	scenarioPath := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(scenarioPath, []byte(e2eScenario), 0644))
+	backend, err := relayprovider.New("fake", config.Backend{Type: "fake", Scenario: scenarioPath})
+	require.NoError(t, err)
+	return backend
+}
+
+// runE2E runs backend to completion in repoDir and returns the run ID.
+func runE2E(t *testing.T, repoDir string, backend func(runID string) relay.Provider) string {
+	t.Helper()
+	ctx := context.Background()
+	defaults := config.DefaultConfig().Defaults
+	defaults.Retry = config.Retry{Strokes: 2, Rotations: 2}
+	r, err := NewRunner(ctx, Config{Cwd: repoDir, Defaults: defaults, Output: Output{Quiet: true}})
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	runID := r.State.RunID
+	require.NoError(t, r.Run(ctx, backend(runID), Models{Fast: config.Model{Name: "fast"}, Slow: config.Model{Name: "slow"}}))
+	return runID
+}
+
+func TestRunner_EndToEnd(t *testing.T) {
+	repoDir := setupE2ERepo(t)
+	backend := newE2EBackend(t)
+	runE2E(t, repoDir, func(string) relay.Provider { return backend })
+
+	subjects := strings.Split(strings.TrimSpace(gitOutput(t, repoDir, "log", "--format=%s")), "\n")
+	assert.Equal(t, []string{"feat: greet the world", "feat: add greeting", "add prd", "initial commit"}, subjects)
+
+	trailers := gitOutput(t, repoDir, "log", "-1", "--skip=1", "--format=%(trailers)")
+	assert.Contains(t, trailers, "Turbine: T-001")
+	assert.Contains(t, trailers, "Turbine-Backend: fake")
+	assert.Contains(t, trailers, "Turbine-Rotation: 2")
+	assert.Contains(t, trailers, "Turbine-Stroke: 1")
+	assert.Contains(t, trailers, "Turbine-Tokens: 300 in, 30 out", "usage of every stroke of the task is counted")
+
+	readme, err := os.ReadFile(filepath.Join(repoDir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Test Repo", string(readme), "the rotation reset discarded the broken attempt")
+	hello, err := os.ReadFile(filepath.Join(repoDir, "hello.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(hello))
+
+	progress, err := os.ReadFile(filepath.Join(repoDir, ProgressRelPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(progress), "T-001 Create greeting - done")
+	assert.Contains(t, string(progress), "T-002 Greet the world - done")
+	assert.Contains(t, string(progress), "DONE No remaining work - done (no remaining work)")
+
+	_, exists, err := state.Load(repoDir)
+	require.NoError(t, err)
+	assert.False(t, exists, "state is cleared after a completed run")
+	assert.Equal(t, 4, backend.(*relayprovider.FakeProvider).Strokes())
+}
+
+func TestRunner_RecordReplay(t *testing.T) {
+	recordDir := setupE2ERepo(t)
+	fake := newE2EBackend(t)
+	runID := runE2E(t, recordDir, func(runID string) relay.Provider {
+		return relayprovider.NewRecorder(fake, recordDir, runID)
+	})
+
+	// Replay the recording in a fresh repo with the same starting tree.
+	replayDir := setupE2ERepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(replayDir, ".turbine", "runs"), 0755))
+	require.NoError(t, os.Rename(filepath.Join(recordDir, ".turbine", "runs", runID), filepath.Join(replayDir, ".turbine", "runs", runID)))
+	replay, err := relayprovider.NewReplay(replayDir, runID)
+	require.NoError(t, err)
+	runE2E(t, replayDir, func(string) relay.Provider { return replay })
+
+	assert.Equal(t, 0, replay.Remaining(), "every recorded call was replayed")
+	assert.Equal(t,
+		gitOutput(t, recordDir, "log", "--format=%s%n%(trailers:key=Turbine-Tokens)"),
+		gitOutput(t, replayDir, "log", "--format=%s%n%(trailers:key=Turbine-Tokens)"))
+	for _, name := range []string{"README.md", "hello.txt"} {
+		recorded, err := os.ReadFile(filepath.Join(recordDir, name))
+		require.NoError(t, err)
+		replayed, err := os.ReadFile(filepath.Join(replayDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, string(recorded), string(replayed), name)
+	}
+}
@@ -0,0 +1,181 @@
+package run
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+)
+
+// Reasons a stroke runs on something other than the run's backend and fast model.
+const (
+	ReasonEscalation = "escalation"
+	ReasonFailover   = "failover"
+)
+
+// StrokesLogName is the per-run log of the backend and model used by each
+// stroke, under the backend artifacts directory.
+const StrokesLogName = "strokes.jsonl"
+
+// BackendFactory returns the provider and models of the configured backend
+// named name. It lets the escalation ladder and failover switch backends.
+type BackendFactory func(name string) (relay.Provider, Models, error)
+
+// StrokeChoice is the backend and model a stroke runs with.
+type StrokeChoice struct {
+	Provider relay.Provider
+	Model    config.Model
+	Reason   string // ReasonEscalation or ReasonFailover; empty for the run's defaults
+}
+
+// label describes a choice for the stroke progress line; the defaults need none.
+func (c StrokeChoice) label() string {
+	if c.Reason == "" || c.Provider == nil {
+		return ""
+	}
+	return fmt.Sprintf(", %s %s/%s", c.Reason, c.Provider.Name(), c.Model.Name)
+}
+
+// strokeRecord is one line of the strokes log.
+type strokeRecord struct {
+	Time     time.Time `json:"time"`
+	TaskID   string    `json:"task_id"`
+	Rotation int       `json:"rotation"`
+	Stroke   int       `json:"stroke"`
+	Backend  string    `json:"backend"`
+	Model    string    `json:"model"`
+	Variant  string    `json:"variant,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// chooseStroke picks the backend and model of the current stroke: the escalation
+// rung of the rotation, on the failover backend if the run has failed over.
+// base and fast are the run's backend and fast model.
+func (r *Runner) chooseStroke(base relay.Provider, fast config.Model) (StrokeChoice, error) {
+	var rung config.Rung
+	if n := len(r.Config.Escalation); n > 0 {
+		rung = r.Config.Escalation[min(max(r.State.Rotation, 1), n)-1]
+	}
+
+	name, reason := base.Name(), ""
+	if rung.Backend != "" && rung.Backend != name {
+		name, reason = rung.Backend, ReasonEscalation
+	}
+	if (rung.Model != "" && rung.Model != "fast") || rung.Variant != "" {
+		reason = ReasonEscalation
+	}
+	explicitModel := true
+	if f := r.State.Failover; f > 0 && f <= len(r.Config.Failover) {
+		// Model names belong to the rung's backend; other backends use the tier.
+		explicitModel = r.Config.Failover[f-1] == name
+		name, reason = r.Config.Failover[f-1], ReasonFailover
+	}
+
+	provider, models, err := r.backend(name, base, fast)
+	if err != nil {
+		return StrokeChoice{}, err
+	}
+
+	var model config.Model
+	switch rung.Model {
+	case "", "fast":
+		model = models.Fast
+	case "slow":
+		model = models.Slow
+	default:
+		model = models.Fast
+		if explicitModel {
+			model = config.Model{Name: rung.Model, Variant: models.Fast.Variant}
+		}
+	}
+	if rung.Variant != "" {
+		model.Variant = rung.Variant
+	}
+	return StrokeChoice{Provider: provider, Model: model, Reason: reason}, nil
+}
+
+// backend returns the provider and models of the backend named name, creating
+// and caching it on first use.
+func (r *Runner) backend(name string, base relay.Provider, fast config.Model) (relay.Provider, Models, error) {
+	if name == base.Name() {
+		slow := r.models.Slow
+		if slow.Name == "" {
+			slow = fast
+		}
+		return base, Models{Fast: fast, Slow: slow}, nil
+	}
+	if b, ok := r.providers[name]; ok {
+		return b.provider, b.models, nil
+	}
+	if r.backends == nil {
+		return nil, Models{}, fmt.Errorf("backend %s is not available", name)
+	}
+	provider, models, err := r.backends(name)
+	if err != nil {
+		return nil, Models{}, fmt.Errorf("backend %s: %w", name, err)
+	}
+	if r.providers == nil {
+		r.providers = make(map[string]cachedBackend)
+	}
+	r.providers[name] = cachedBackend{provider: provider, models: models}
+	return provider, models, nil
+}
+
+type cachedBackend struct {
+	provider relay.Provider
+	models   Models
+}
+
+// failover switches the run to the next usable failover backend after the
+// backend named from failed with a provider error. It reports whether it switched.
+func (r *Runner) failover(from string, base relay.Provider, fast config.Model, cause error) bool {
+	for r.State.Failover < len(r.Config.Failover) {
+		r.State.Failover++
+		name := r.Config.Failover[r.State.Failover-1]
+		if name == from {
+			continue
+		}
+		if _, _, err := r.backend(name, base, fast); err != nil {
+			r.warnf("Skipping failover backend %s: %v", name, err)
+			continue
+		}
+		r.warnf("Backend %s failed (%v); failing over to %s", from, cause, name)
+		r.State.BackendSessionID = ""
+		if err := state.Save(r.RepoRoot, r.State); err != nil {
+			r.warnf("State not saved: %v", err)
+		}
+		return true
+	}
+	return false
+}
+
+// recordStroke appends the stroke's choice to the strokes log.
+func (r *Runner) recordStroke(arts *Artifacts, taskID string, choice StrokeChoice) error {
+	data, err := json.Marshal(strokeRecord{
+		Time:     time.Now().UTC(),
+		TaskID:   taskID,
+		Rotation: r.State.Rotation,
+		Stroke:   r.State.Stroke,
+		Backend:  choice.Provider.Name(),
+		Model:    choice.Model.Name,
+		Variant:  choice.Model.Variant,
+		Reason:   choice.Reason,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal stroke record: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(arts.Root(), SubDirBackend, StrokesLogName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open strokes log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("append strokes log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+// namedProvider is a mockProvider reporting another backend name.
+type namedProvider struct {
+	*mockProvider
+	name string
+}
+
+func (p *namedProvider) Name() string { return p.name }
+
+func TestChooseStroke(t *testing.T) {
+	base := &mockProvider{}
+	backup := &namedProvider{mockProvider: &mockProvider{}, name: "backup"}
+	created := 0
+	r := &Runner{
+		State: &state.RunState{},
+		Config: config.Defaults{
+			Escalation: []config.Rung{{}, {Model: "slow"}, {Backend: "backup", Model: "huge", Variant: "max"}},
+			Failover:   []string{"backup"},
+		},
+		models: Models{Slow: config.Model{Name: "opus"}},
+		backends: func(name string) (relay.Provider, Models, error) {
+			created++
+			if name != "backup" {
+				return nil, Models{}, fmt.Errorf("unknown backend: %s", name)
+			}
+			return backup, Models{Fast: config.Model{Name: "b-fast"}, Slow: config.Model{Name: "b-slow"}}, nil
+		},
+	}
+	fast := config.Model{Name: "sonnet", Variant: "low"}
+
+	tests := []struct {
+		rotation, failover int
+		backend, model     string
+		variant, reason    string
+	}{
+		{rotation: 1, backend: "mock", model: "sonnet", variant: "low"},
+		{rotation: 2, backend: "mock", model: "opus", reason: ReasonEscalation},
+		{rotation: 3, backend: "backup", model: "huge", variant: "max", reason: ReasonEscalation},
+		{rotation: 5, backend: "backup", model: "huge", variant: "max", reason: ReasonEscalation},
+		{rotation: 1, failover: 1, backend: "backup", model: "b-fast", reason: ReasonFailover},
+		{rotation: 2, failover: 1, backend: "backup", model: "b-slow", reason: ReasonFailover},
+		{rotation: 3, failover: 1, backend: "backup", model: "huge", variant: "max", reason: ReasonFailover},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("rotation %d failover %d", tt.rotation, tt.failover), func(t *testing.T) {
+			r.State.Rotation, r.State.Failover = tt.rotation, tt.failover
+			choice, err := r.chooseStroke(base, fast)
+			require.NoError(t, err)
+			assert.Equal(t, tt.backend, choice.Provider.Name())
+			assert.Equal(t, config.Model{Name: tt.model, Variant: tt.variant}, choice.Model)
+			assert.Equal(t, tt.reason, choice.Reason)
+		})
+	}
+	assert.Equal(t, 1, created, "backends are created once")
+
+	r.Config.Escalation = []config.Rung{{Backend: "missing"}}
+	r.State.Failover = 0
+	_, err := r.chooseStroke(base, fast)
+	require.EqualError(t, err, "backend missing: unknown backend: missing")
+}
+
+func TestExecuteTask_EscalationAndFailover(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	donePath := filepath.Join(repoDir, "done.txt")
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"test -f " + donePath},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(repoDir, TaskRelPath)))
+
+	var models []string
+	primary := &mockProvider{runFunc: func(_ context.Context, params relay.RunParams, _ chan<- relay.Event) error {
+		models = append(models, params.Model)
+		if params.Model == "opus" {
+			return errors.New("rate limited")
+		}
+		return nil // The fast model does nothing, so verification fails.
+	}}
+	backup := &namedProvider{name: "backup", mockProvider: &mockProvider{runFunc: func(_ context.Context, params relay.RunParams, _ chan<- relay.Event) error {
+		models = append(models, params.Model)
+		return os.WriteFile(donePath, []byte("done\n"), 0644)
+	}}}
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config: config.Defaults{
+			Retry:      config.Retry{Strokes: 1, Rotations: 3, Transient: config.Backoff{Attempts: 1, Initial: time.Millisecond}},
+			Commit:     config.Commit{Trailers: []string{"backend", "model"}},
+			Escalation: []config.Rung{{}, {Model: "slow"}},
+			Failover:   []string{"backup"},
+		},
+		models: Models{Slow: config.Model{Name: "opus"}},
+		backends: func(name string) (relay.Provider, Models, error) {
+			return backup, Models{Fast: config.Model{Name: "b-fast"}, Slow: config.Model{Name: "b-slow"}}, nil
+		},
+	}
+	require.NoError(t, r.ExecuteTask(ctx, primary, "sonnet", ""))
+
+	assert.Equal(t, []string{"sonnet", "opus", "opus", "b-slow"}, models, "the stroke is retried once, then on the failover backend with the same tier")
+	assert.Equal(t, 1, r.State.Failover)
+
+	msg := gitOutput(t, repoDir, "log", "-1", "--pretty=%B")
+	assert.Contains(t, msg, "Turbine-Backend: backup")
+	assert.Contains(t, msg, "Turbine-Model: b-slow")
+
+	data, err := os.ReadFile(filepath.Join(repoDir, RunsDir, "test-run", SubDirBackend, StrokesLogName))
+	require.NoError(t, err)
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec strokeRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		got = append(got, fmt.Sprintf("%d/%d %s %s %s", rec.Rotation, rec.Stroke, rec.Backend, rec.Model, rec.Reason))
+	}
+	assert.Equal(t, []string{
+		"1/1 mock sonnet ",
+		"2/1 mock opus escalation",
+		"2/1 mock opus escalation",
+		"2/1 backup b-slow failover",
+	}, got)
+}
+
+func TestExecuteTask_NoFailoverOnVerification(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"false"},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(repoDir, TaskRelPath)))
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config: config.Defaults{
+			Retry:    config.Retry{Strokes: 2, Rotations: 1},
+			Failover: []string{"backup"},
+		},
+		backends: func(name string) (relay.Provider, Models, error) {
+			t.Fatalf("failover backend %s created for a verification failure", name)
+			return nil, Models{}, nil
+		},
+	}
+	require.Error(t, r.ExecuteTask(ctx, &mockProvider{}, "sonnet", ""))
+	assert.Equal(t, 0, r.State.Failover)
+}
@@ -2,14 +2,19 @@ package run
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/atomicfile"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/decomposer"
 	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/notify"
 	filestore "github.com/yarlson/turbine/internal/relay/store"
 	"github.com/yarlson/turbine/internal/relay/stream"
+	"github.com/yarlson/turbine/internal/state"
 	"github.com/yarlson/turbine/internal/tasks"
 	"github.com/yarlson/turbine/internal/ui"
 )
@@ -28,11 +33,16 @@ func (r *Runner) ExecuteTaskWithTask(ctx context.Context, backend relay.Provider
 		return fmt.Errorf("no active task file loaded")
 	}
 
-	fmt.Printf("%s %s\n", ui.Section("›", ui.Bold(task.Title)), ui.Dim(fmt.Sprintf("[%s]", task.ID)))
+	r.printf("%s %s\n", ui.Section("›", ui.Bold(task.Title)), ui.Dim(fmt.Sprintf("[%s]", task.ID)))
 
-	policy := &RetryPolicy{
-		MaxStrokes:   r.Config.Retry.Strokes,
-		MaxRotations: r.Config.Retry.Rotations,
+	if err := messagePolicy(r.Config.Commit.Message).Validate(task.CommitMessage); err != nil {
+		if task, err = r.fixTask(ctx, backend, task, err); err != nil {
+			return err
+		}
+	}
+
+	if err := r.runHooks(ctx, HookPreTask, r.Config.Hooks.PreTask, hookContext{TaskID: task.ID, Title: task.Title}); err != nil {
+		return err
 	}
 
 	// Artifacts setup
@@ -41,10 +51,41 @@ func (r *Runner) ExecuteTaskWithTask(ctx context.Context, backend relay.Provider
 		return fmt.Errorf("set up artifacts: %w", err)
 	}
 
+	// The backend and model of the current stroke, picked before it starts.
+	fast := config.Model{Name: model, Variant: variant}
+	var choice StrokeChoice
+	policy := &RetryPolicy{
+		MaxStrokes:   r.Config.Retry.Strokes,
+		MaxRotations: r.Config.Retry.Rotations,
+		Choose: func() (StrokeChoice, error) {
+			c, err := r.chooseStroke(backend, fast)
+			if err != nil {
+				return c, err
+			}
+			choice = c
+			if err := r.recordStroke(arts, task.ID, c); err != nil {
+				r.warnf("Stroke choice not recorded: %v", err)
+			}
+			return c, nil
+		},
+		Backoff: r.Config.Retry.Transient,
+		Failover: func(err *BackendError) bool {
+			return r.failover(err.Backend, backend, fast, err)
+		},
+	}
+
 	// Track failure output for retry context
 	var lastFailureOutput string
+	// Track verification results and usage for savepoint metadata
+	var lastVerifyResults []VerifyResult
+	var usage usageTotals
 
 	err = policy.Execute(ctx, r, task, func(ctx context.Context) error {
+		// Set when the stroke fails in verification or a hook rather than in the backend.
+		var postHookFailed bool
+		// The last error reported in the backend's events, used to classify failures.
+		var eventError string
+
 		// Determine phase based on current stroke and rotation
 		isRetry := r.State.Stroke > 1 || r.State.Rotation > 1
 		execCtx := promptContext{
@@ -66,27 +107,56 @@ func (r *Runner) ExecuteTaskWithTask(ctx context.Context, backend relay.Provider
 
 		workflowID := fmt.Sprintf("%s-%s", r.State.RunID, task.ID)
 		store := filestore.New(r.RepoRoot)
-		exec := relay.NewExecutor(backend, relay.WithStore(store))
+		exec := relay.NewExecutor(r.strokeProvider(choice.Provider), relay.WithStore(store))
 		workflow := &relay.Workflow{
 			ID:         workflowID,
 			WorkingDir: r.RepoRoot,
-			Model:      model,
-			Variant:    variant,
+			Model:      choice.Model.Name,
+			Variant:    choice.Model.Variant,
 			Sessions: []relay.Session{
 				{
 					Steps: []relay.Step{
 						{
-							Prompt:   fullPrompt,
-							Continue: r.State.Stroke > 1,
+							// The session is never continued here: strokeProvider resumes the
+							// recorded session, if any, and falls back to a new one.
+							Prompt: fullPrompt,
 							PostHook: func(_ *relay.StepContext, _ *relay.StepResult) error {
-								fmt.Printf("  %s\n", ui.InProgressMarker()+" Verifying...")
-								_, verifyErr := RunVerification(ctx, arts, task.Verify)
+								if hookErr := r.runHooks(ctx, HookPreVerify, r.Config.Hooks.PreVerify, r.strokeHookContext(task, "")); hookErr != nil {
+									r.printf("  %s %s\n", ui.FailureMarker(), ui.Dim(hookErr.Error()))
+									lastFailureOutput = hookFailureOutput(hookErr)
+									postHookFailed = true
+									return hookErr
+								}
+
+								r.printf("  %s\n", ui.InProgressMarker()+" Verifying...")
+								results, verifyErr := RunVerification(ctx, arts, task.Verify)
+								lastVerifyResults = results
+								verifyStatus := StatusPassed
 								if verifyErr != nil {
-									fmt.Printf("  %s\n", ui.FailureMarker()+" Verification failed")
+									verifyStatus = StatusFailed
+								}
+								r.emit(LifecycleEvent{
+									Event:    EventVerifyResult,
+									TaskID:   task.ID,
+									Rotation: r.State.Rotation,
+									Stroke:   r.State.Stroke,
+									Status:   verifyStatus,
+									Verify:   verifyEntries(r.RepoRoot, results, verifyErr),
+								})
+								if verifyErr != nil {
+									r.printf("  %s\n", ui.FailureMarker()+" Verification failed")
 									lastFailureOutput = verifyErr.Error()
+									postHookFailed = true
 									return fmt.Errorf("verification failed: %w", verifyErr)
 								}
-								fmt.Printf("  %s\n", ui.SuccessMarker()+" Verification passed")
+								r.printf("  %s\n", ui.SuccessMarker()+" Verification passed")
+
+								if hookErr := r.runHooks(ctx, HookPreCommit, r.Config.Hooks.PreCommit, r.strokeHookContext(task, "")); hookErr != nil {
+									r.printf("  %s %s\n", ui.FailureMarker(), ui.Dim(hookErr.Error()))
+									lastFailureOutput = hookFailureOutput(hookErr)
+									postHookFailed = true
+									return hookErr
+								}
 								return nil
 							},
 						},
@@ -95,34 +165,90 @@ func (r *Runner) ExecuteTaskWithTask(ctx context.Context, backend relay.Provider
 			},
 		}
 
-		if err := runWorkflow(ctx, exec, workflow, store); err != nil {
-			return fmt.Errorf("backend failed: %w", err)
+		onEvent := func(evt relay.Event) {
+			if evt.Error != "" {
+				eventError = evt.Error
+			}
+			usage.add(evt)
+			r.addUsage(evt)
+			r.recordSession(choice.Provider.Name(), evt)
+			r.streamEvent(evt)
+		}
+		err := runWorkflow(ctx, exec, workflow, store, onEvent)
+
+		strokeStatus := StatusPassed
+		if err != nil {
+			strokeStatus = StatusFailed
 		}
+		r.runAdvisoryHooks(ctx, HookPostStroke, r.Config.Hooks.PostStroke, r.strokeHookContext(task, strokeStatus))
 
+		if err != nil {
+			if !postHookFailed && ctx.Err() == nil {
+				return &BackendError{
+					Backend: choice.Provider.Name(),
+					Class:   ClassifyBackendError(err.Error(), eventError),
+					Err:     err,
+				}
+			}
+			return fmt.Errorf("backend failed: %w", err)
+		}
 		return nil
 	})
 
+	// Stopping for the budget or a fatal backend error is not a task failure:
+	// keep the task and state for resume.
+	var budgetErr *BudgetExceededError
+	var backendErr *BackendError
+	if errors.As(err, &budgetErr) || (errors.As(err, &backendErr) && backendErr.Class.Fatal()) {
+		if saveErr := state.Save(r.RepoRoot, r.State); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
+
 	// Save task status (either Done if err == nil, or Failed if policy returned error)
 	if err == nil {
 		task.Status = tasks.StatusDone
 	} else {
 		// policy.Execute already sets task.Status = tasks.StatusFailed on exhaustion
-		fmt.Printf("%s %s\n  %s\n", ui.FailureMarker(), ui.Bold(task.Title), ui.Red(fmt.Sprintf("Failed after max rotations: %v", err)))
+		fmt.Fprintf(r.Output.HumanWriter(), "%s %s\n  %s\n", ui.FailureMarker(), ui.Bold(task.Title), ui.Red(fmt.Sprintf("Failed after max rotations: %v", err)))
 	}
 
 	taskPath := filepath.Join(r.RepoRoot, TaskRelPath)
-	if saveErr := r.TaskFile.Save(taskPath); saveErr != nil {
+	taskBackupPath := filepath.Join(r.RepoRoot, TaskBackupRelPath)
+	if saveErr := r.TaskFile.SaveWithBackup(taskPath, taskBackupPath); saveErr != nil {
 		return fmt.Errorf("save task: %w", saveErr)
 	}
 
 	if err == nil {
 		// Commit changes on success
-		footer := fmt.Sprintf("Turbine: %s", task.ID)
-		hash, commitErr := gitx.CommitSavePoint(ctx, r.RepoRoot, task.CommitMessage, footer)
+		trailers := commitTrailers(r.Config.Commit.Trailers, savepointMeta{
+			TaskID:   task.ID,
+			RunID:    r.State.RunID,
+			Backend:  choice.Provider.Name(),
+			Model:    choice.Model.Name,
+			Variant:  choice.Model.Variant,
+			Rotation: r.State.Rotation,
+			Stroke:   r.State.Stroke,
+			Usage:    usage,
+		})
+		hash, commitErr := gitx.CommitSavePointWithOptions(ctx, r.RepoRoot, task.CommitMessage, commitOptions(r.Config.Commit, *task, trailers))
 		if commitErr != nil {
 			return fmt.Errorf("commit changes: %w", commitErr)
 		}
-		fmt.Printf("  %s %s\n", ui.SuccessMarker(), ui.Dim(hash))
+		r.printf("  %s %s\n", ui.SuccessMarker(), ui.Dim(hash))
+		r.emit(LifecycleEvent{Event: EventCommit, TaskID: task.ID, Rotation: r.State.Rotation, Stroke: r.State.Stroke, Commit: hash})
+		postCommit := r.strokeHookContext(task, StatusPassed)
+		postCommit.Commit = hash
+		r.runAdvisoryHooks(ctx, HookPostCommit, r.Config.Hooks.PostCommit, postCommit)
+
+		if r.Config.Commit.Notes {
+			if noteErr := r.addCommitNote(ctx, hash, lastVerifyResults); noteErr != nil {
+				r.warnf("Commit note not saved: %v", noteErr)
+			}
+		}
+
+		r.notify(ctx, notify.Event{Event: notify.EventTaskDone, TaskID: task.ID, Title: task.Title, Status: StatusDone, Commit: hash})
 
 		entry := fmt.Sprintf("- %s %s %s - done (commit %s)", timeNowUTC(), task.ID, task.Title, hash)
 		if err := AppendProgress(r.RepoRoot, entry); err != nil {
@@ -131,7 +257,7 @@ func (r *Runner) ExecuteTaskWithTask(ctx context.Context, backend relay.Provider
 		if _, err := ArchiveTaskFile(r.RepoRoot, r.TaskFile); err != nil {
 			return err
 		}
-		if err := os.Remove(taskPath); err != nil && !os.IsNotExist(err) {
+		if err := atomicfile.Remove(taskPath, taskBackupPath); err != nil {
 			return fmt.Errorf("remove task file: %w", err)
 		}
 
@@ -146,22 +272,60 @@ func (r *Runner) ExecuteTaskWithTask(ctx context.Context, backend relay.Provider
 		if progressErr := AppendProgress(r.RepoRoot, entry); progressErr != nil {
 			return progressErr
 		}
+		r.notify(ctx, notify.Event{Event: notify.EventTaskFailed, TaskID: task.ID, Title: task.Title, Status: StatusFailed, Error: err.Error()})
 	}
 
+	taskStatus := StatusDone
+	if err != nil {
+		taskStatus = StatusFailed
+	}
+	r.emit(LifecycleEvent{Event: EventTaskEnd, TaskID: task.ID, Title: task.Title, Status: taskStatus, Error: errorString(err)})
 	return err
 }
 
-func runWorkflow(ctx context.Context, exec *relay.Executor, workflow *relay.Workflow, store *filestore.FileStore) error {
+// fixTask sends a task whose commit_message breaks the message policy, such as
+// one planned before the policy was tightened, back to the planner's fix prompt.
+// It returns the fixed task, which replaces the loaded task file.
+func (r *Runner) fixTask(ctx context.Context, backend relay.Provider, task *tasks.Task, invalid error) (*tasks.Task, error) {
+	r.warnf("Task %s commit_message: %v; asking the planner to fix it", task.ID, invalid)
+
+	taskPath := filepath.Join(r.RepoRoot, TaskRelPath)
+	if err := r.TaskFile.SaveWithBackup(taskPath, filepath.Join(r.RepoRoot, TaskBackupRelPath)); err != nil {
+		return nil, fmt.Errorf("save task: %w", err)
+	}
+	opts := PlanOptionsFromModels(r.models)
+	opts.MessagePolicy = messagePolicy(r.Config.Commit.Message)
+	opts.OnEvent = r.addUsage
+	if err := decomposer.New(backend, r.RepoRoot).FixTask(ctx, r.PRDPath, r.ProgressPath, opts); err != nil {
+		return nil, fmt.Errorf("task %s commit_message: %w", task.ID, err)
+	}
+	fixed, err := tasks.LoadTaskFile(taskPath)
+	if err != nil {
+		return nil, err
+	}
+	*r.TaskFile = *fixed
+	return &r.TaskFile.Task, nil
+}
+
+// addCommitNote attaches the task file and verification summary to a savepoint commit.
+func (r *Runner) addCommitNote(ctx context.Context, hash string, results []VerifyResult) error {
+	note, err := buildCommitNote(r.RepoRoot, r.State.RunID, r.TaskFile, results)
+	if err != nil {
+		return err
+	}
+	return gitx.AddNote(ctx, r.RepoRoot, NotesRef, hash, note)
+}
+
+// runWorkflow runs a workflow, persisting events to store and passing each one to onEvent (if set).
+func runWorkflow(ctx context.Context, exec *relay.Executor, workflow *relay.Workflow, store *filestore.FileStore, onEvent func(relay.Event)) error {
 	events := make(chan relay.Event, 256)
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		if store == nil || workflow.ID == "" {
-			for range events {
-			}
-			return
-		}
 		for evt := range events {
+			if onEvent != nil {
+				onEvent(evt)
+			}
 			stream.AppendEvent(ctx, store, workflow.ID, evt)
 		}
 	}()
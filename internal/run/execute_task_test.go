@@ -3,7 +3,9 @@ package run
 import (
 	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	relay "github.com/yarlson/relay"
@@ -80,6 +82,64 @@ func TestExecuteTask_Success(t *testing.T) {
 	// We can't easily check the commit without shelling out, but setupTestRepo already uses git.
 }
 
+func TestExecuteTask_CommitMetadata(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+
+	tasksDir := filepath.Join(repoDir, ".turbine")
+	require.NoError(t, os.MkdirAll(tasksDir, 0755))
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"true"},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(tasksDir, "task.yaml")))
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config: config.Defaults{
+			Retry:  config.Retry{Strokes: 3, Rotations: 3},
+			Commit: config.Commit{Trailers: []string{"run", "backend", "model", "cost"}, Notes: true},
+		},
+	}
+
+	mock := &mockProvider{runFunc: func(_ context.Context, _ relay.RunParams, events chan<- relay.Event) error {
+		events <- relay.Event{Kind: relay.EventKindText, Usage: &relay.Usage{InputTokens: 10, OutputTokens: 20, CostUSD: 0.5}}
+		return nil
+	}}
+
+	require.NoError(t, r.ExecuteTask(ctx, mock, "sonnet", ""))
+
+	msg := gitOutput(t, repoDir, "log", "-1", "--pretty=%B")
+	assert.Contains(t, msg, "feat: task 1\n\nTurbine: T1\n")
+	assert.Contains(t, msg, "Turbine-Run: test-run")
+	assert.Contains(t, msg, "Turbine-Backend: mock")
+	assert.Contains(t, msg, "Turbine-Model: sonnet")
+	assert.Contains(t, msg, "Turbine-Cost: 0.5000 USD")
+
+	note := gitOutput(t, repoDir, "notes", "--ref", NotesRef, "show", "HEAD")
+	assert.Contains(t, note, "run: test-run")
+	assert.Contains(t, note, "id: T1")
+	assert.Contains(t, note, "command: \"true\"")
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, string(out))
+	return string(out)
+}
+
 func TestExecuteTask_VerifyFailure(t *testing.T) {
 	ctx := context.Background()
 	repoDir := setupTestRepo(t)
@@ -119,3 +179,68 @@ func TestExecuteTask_VerifyFailure(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, tasks.StatusFailed, updatedTasks.Task.Status)
 }
+
+func TestExecuteTask_CommitMessagePolicy(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "Add task 1",
+			Verify:        []string{"true"},
+		},
+	}
+
+	prdPath := filepath.Join(repoDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# PRD"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		PRDPath:  prdPath,
+		Config: config.Defaults{
+			Retry:  config.Retry{Strokes: 1, Rotations: 1},
+			Commit: config.Commit{Message: config.CommitMessage{Conventional: true}},
+		},
+	}
+
+	// The first call is the planner's fix; the rest are strokes.
+	var prompts []string
+	mock := &mockProvider{runFunc: func(_ context.Context, params relay.RunParams, _ chan<- relay.Event) error {
+		prompts = append(prompts, params.Prompt)
+		if len(prompts) == 1 {
+			fixed := *taskFile
+			fixed.Task.CommitMessage = "feat: task 1"
+			return fixed.Save(filepath.Join(params.WorkingDir, TaskRelPath))
+		}
+		return nil
+	}}
+
+	require.NoError(t, r.ExecuteTask(ctx, mock, "sonnet", ""))
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "Fix Invalid .turbine/task.yaml")
+	assert.Contains(t, prompts[0], "does not follow Conventional Commits")
+	assert.Equal(t, "feat: task 1", strings.TrimSpace(gitOutput(t, repoDir, "log", "-1", "--format=%s")))
+
+	t.Run("planner cannot fix it", func(t *testing.T) {
+		r.TaskFile = &tasks.TaskFile{Version: 1, Task: taskFile.Task}
+		r.TaskFile.Task.CommitMessage = "Add task 1"
+		r.TaskFile.Task.Status = tasks.StatusTodo
+		r.State = &state.RunState{RunID: "test-run-2"}
+		calls := 0
+		mock := &mockProvider{runFunc: func(context.Context, relay.RunParams, chan<- relay.Event) error {
+			calls++
+			return nil
+		}}
+
+		err := r.ExecuteTask(ctx, mock, "sonnet", "")
+		assert.ErrorContains(t, err, "does not follow Conventional Commits")
+		assert.Equal(t, 2, calls, "only fix prompts run, no strokes")
+	})
+}
@@ -0,0 +1,153 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+// Hook points, as named in defaults.hooks.
+const (
+	HookPrePlan    = "pre_plan"
+	HookPreTask    = "pre_task"
+	HookPostStroke = "post_stroke"
+	HookPreVerify  = "pre_verify"
+	HookPreCommit  = "pre_commit"
+	HookPostCommit = "post_commit"
+)
+
+// hookContext describes where in the run a hook fires. Empty fields are passed
+// to the hook as empty variables.
+type hookContext struct {
+	TaskID   string
+	Title    string
+	Rotation int
+	Stroke   int
+	Status   string // post_stroke: passed or failed
+	Commit   string // post_commit: the savepoint commit
+}
+
+// HookError is returned when a hook command exits non-zero.
+type HookError struct {
+	Hook     string
+	Command  string
+	ExitCode int
+	LogPath  string
+	Output   string
+	Err      error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook %q exited %d (see %s): %v", e.Hook, e.Command, e.ExitCode, e.LogPath, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// runHooks runs the commands of a hook point in the repository root, saving each
+// command's output under the run's hooks/ artifacts. It stops at the first
+// failing command and returns a *HookError.
+func (r *Runner) runHooks(ctx context.Context, hook string, commands []string, hc hookContext) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	arts, err := NewArtifacts(r.RepoRoot, r.State.RunID)
+	if err != nil {
+		return fmt.Errorf("set up artifacts: %w", err)
+	}
+
+	env := append(os.Environ(), r.hookEnv(hook, arts, hc)...)
+	for i, command := range commands {
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+		cmd.Dir = r.RepoRoot
+		cmd.Env = env
+		output, runErr := cmd.CombinedOutput()
+
+		logPath, logErr := arts.WriteFile(SubDirHooks, hookLogName(hook, hc, i), string(output))
+		if logErr != nil {
+			return fmt.Errorf("write hook log: %w", logErr)
+		}
+		if runErr != nil {
+			exitCode := -1
+			if exitErr, ok := runErr.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			}
+			return &HookError{
+				Hook:     hook,
+				Command:  command,
+				ExitCode: exitCode,
+				LogPath:  logPath,
+				Output:   string(output),
+				Err:      runErr,
+			}
+		}
+	}
+	return nil
+}
+
+// runAdvisoryHooks runs a non-gating hook point, reporting failures as warnings.
+func (r *Runner) runAdvisoryHooks(ctx context.Context, hook string, commands []string, hc hookContext) {
+	if err := r.runHooks(ctx, hook, commands, hc); err != nil {
+		r.warnf("%v", err)
+	}
+}
+
+// hookEnv returns the TURBINE_* variables describing the run and task to a hook.
+func (r *Runner) hookEnv(hook string, arts *Artifacts, hc hookContext) []string {
+	return []string{
+		"TURBINE_HOOK=" + hook,
+		"TURBINE_REPO=" + r.RepoRoot,
+		"TURBINE_RUN_ID=" + r.State.RunID,
+		"TURBINE_ARTIFACTS=" + arts.Root(),
+		"TURBINE_TASK_ID=" + hc.TaskID,
+		"TURBINE_TASK_TITLE=" + hc.Title,
+		"TURBINE_ROTATION=" + strconv.Itoa(hc.Rotation),
+		"TURBINE_STROKE=" + strconv.Itoa(hc.Stroke),
+		"TURBINE_STATUS=" + hc.Status,
+		"TURBINE_COMMIT=" + hc.Commit,
+	}
+}
+
+// hookLogName names the log of the i-th command of a hook point, e.g.
+// T-001-r1-s2-pre_verify-01.log. Hooks outside a task are named by time.
+func hookLogName(hook string, hc hookContext, i int) string {
+	parts := []string{}
+	if hc.TaskID != "" {
+		parts = append(parts, hc.TaskID)
+	} else {
+		parts = append(parts, time.Now().UTC().Format("20060102-150405"))
+	}
+	if hc.Rotation > 0 {
+		parts = append(parts, fmt.Sprintf("r%d", hc.Rotation), fmt.Sprintf("s%d", hc.Stroke))
+	}
+	parts = append(parts, hook, fmt.Sprintf("%02d", i+1))
+	return strings.Join(parts, "-") + ".log"
+}
+
+// strokeHookContext describes the current stroke of task to a hook.
+func (r *Runner) strokeHookContext(task *tasks.Task, status string) hookContext {
+	return hookContext{
+		TaskID:   task.ID,
+		Title:    task.Title,
+		Rotation: r.State.Rotation,
+		Stroke:   r.State.Stroke,
+		Status:   status,
+	}
+}
+
+// hookFailureOutput describes a failed gating hook to the agent's next stroke.
+func hookFailureOutput(err error) string {
+	var hookErr *HookError
+	if errors.As(err, &hookErr) && strings.TrimSpace(hookErr.Output) != "" {
+		return fmt.Sprintf("%v\n\n%s", hookErr, strings.TrimSpace(hookErr.Output))
+	}
+	return err.Error()
+}
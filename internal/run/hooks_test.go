@@ -0,0 +1,113 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+func TestRunner_RunHooks(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	r := &Runner{RepoRoot: repoDir, State: &state.RunState{RunID: "test-run"}}
+	hc := hookContext{TaskID: "T1", Title: "Task 1", Rotation: 1, Stroke: 2}
+
+	err := r.runHooks(ctx, HookPreVerify, []string{`echo "$TURBINE_HOOK $TURBINE_TASK_ID $TURBINE_STROKE $(pwd)"`}, hc)
+	require.NoError(t, err)
+	logPath := filepath.Join(repoDir, RunsDir, "test-run", SubDirHooks, "T1-r1-s2-pre_verify-01.log")
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	resolved, err := filepath.EvalSymlinks(repoDir)
+	require.NoError(t, err)
+	assert.Contains(t, []string{"pre_verify T1 2 " + repoDir + "\n", "pre_verify T1 2 " + resolved + "\n"}, string(data))
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	err = r.runHooks(ctx, HookPreCommit, []string{"echo lint failed; exit 3", "touch " + marker}, hc)
+	var hookErr *HookError
+	require.ErrorAs(t, err, &hookErr)
+	assert.Equal(t, HookPreCommit, hookErr.Hook)
+	assert.Equal(t, 3, hookErr.ExitCode)
+	assert.Contains(t, hookFailureOutput(err), "lint failed")
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr), "commands after a failure do not run")
+}
+
+func TestExecuteTask_Hooks(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	tasksDir := filepath.Join(repoDir, ".turbine")
+	require.NoError(t, os.MkdirAll(tasksDir, 0755))
+
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			Verify:        []string{"true"},
+			CommitMessage: "feat: task 1",
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(tasksDir, "task.yaml")))
+
+	out := t.TempDir()
+	trace := filepath.Join(out, "trace")
+	gate := filepath.Join(out, "gate")
+	record := func(name string) string {
+		return fmt.Sprintf(`echo %s $TURBINE_STROKE $TURBINE_STATUS >> %s`, name, trace)
+	}
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State:    &state.RunState{RunID: "test-run"},
+		Config: config.Defaults{
+			Retry: config.Retry{Strokes: 2, Rotations: 1},
+			Hooks: config.Hooks{
+				PreTask:    []string{record("pre_task")},
+				PreVerify:  []string{record("pre_verify")},
+				PreCommit:  []string{record("pre_commit"), fmt.Sprintf("test -f %s || { touch %s; exit 1; }", gate, gate)},
+				PostStroke: []string{record("post_stroke")},
+				PostCommit: []string{record("post_commit"), fmt.Sprintf(`echo "$TURBINE_COMMIT" > %s`, filepath.Join(out, "commit"))},
+			},
+		},
+		Output: Output{Quiet: true},
+	}
+
+	var prompts []string
+	mock := &mockProvider{runFunc: func(_ context.Context, params relay.RunParams, _ chan<- relay.Event) error {
+		prompts = append(prompts, params.Prompt)
+		return nil
+	}}
+	require.NoError(t, r.ExecuteTask(ctx, mock, "model", ""))
+
+	data, err := os.ReadFile(trace)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"pre_task 0",
+		"pre_verify 1",
+		"pre_commit 1",
+		"post_stroke 1 failed",
+		"pre_verify 2",
+		"pre_commit 2",
+		"post_stroke 2 passed",
+		"post_commit 2 passed",
+	}, strings.Split(strings.TrimSpace(string(data)), "\n"))
+
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[1], "pre_commit hook", "the retry prompt explains the failed hook")
+
+	commit, err := os.ReadFile(filepath.Join(out, "commit"))
+	require.NoError(t, err)
+	assert.Equal(t, gitOutput(t, repoDir, "rev-parse", "HEAD"), string(commit))
+}
@@ -0,0 +1,122 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lifecycle event names emitted with --output json. They are part of the
+// machine-readable interface and must not change.
+const (
+	EventRunStart      = "run_start"
+	EventTaskPlanned   = "task_planned"
+	EventStrokeStart   = "stroke_start"
+	EventStrokeEnd     = "stroke_end"
+	EventVerifyResult  = "verify_result"
+	EventRotationReset = "rotation_reset"
+	EventCommit        = "commit"
+	EventTaskEnd       = "task_end"
+	EventRunEnd        = "run_end"
+)
+
+// Lifecycle event statuses.
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+	StatusDone   = "done"
+)
+
+// LifecycleEvent is one JSON line written to stdout in JSON output mode.
+// Fields that do not apply to an event are omitted.
+type LifecycleEvent struct {
+	Event     string        `json:"event"`
+	Time      time.Time     `json:"time"`
+	RunID     string        `json:"run_id"`
+	TaskID    string        `json:"task_id,omitempty"`
+	Title     string        `json:"title,omitempty"`
+	Backend   string        `json:"backend,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	Variant   string        `json:"variant,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+	Rotation  int           `json:"rotation,omitempty"`
+	Stroke    int           `json:"stroke,omitempty"`
+	Status    string        `json:"status,omitempty"`
+	Resumed   bool          `json:"resumed,omitempty"`
+	Savepoint string        `json:"savepoint,omitempty"`
+	Commit    string        `json:"commit,omitempty"`
+	Verify    []VerifyEntry `json:"verify,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// VerifyEntry reports one verification command in a verify_result event.
+type VerifyEntry struct {
+	Command    string `json:"command"`
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+	Log        string `json:"log"`
+}
+
+// HumanWriter returns where human-oriented output goes: stderr in JSON mode,
+// so stdout carries only lifecycle events.
+func (o Output) HumanWriter() io.Writer {
+	if o.JSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// emit writes evt as a JSON line in JSON output mode.
+func (r *Runner) emit(evt LifecycleEvent) {
+	if !r.Output.JSON {
+		return
+	}
+	evt.Time = time.Now().UTC()
+	if r.State != nil {
+		evt.RunID = r.State.RunID
+	}
+	w := r.events
+	if w == nil {
+		w = os.Stdout
+	}
+	if err := json.NewEncoder(w).Encode(evt); err != nil {
+		r.warnf("Lifecycle event not written: %v", err)
+	}
+}
+
+// verifyEntries converts verification results into verify_result entries.
+// verifyErr, if set, belongs to the last result.
+func verifyEntries(repoRoot string, results []VerifyResult, verifyErr error) []VerifyEntry {
+	entries := make([]VerifyEntry, 0, len(results))
+	for _, res := range results {
+		logPath := res.LogPath
+		if rel, err := filepath.Rel(repoRoot, res.LogPath); err == nil {
+			logPath = filepath.ToSlash(rel)
+		}
+		entries = append(entries, VerifyEntry{
+			Command:    res.Command,
+			Status:     StatusPassed,
+			DurationMS: res.Duration.Milliseconds(),
+			Log:        logPath,
+		})
+	}
+	var ve *VerifyError
+	if errors.As(verifyErr, &ve) && len(entries) > 0 {
+		last := &entries[len(entries)-1]
+		last.Status = StatusFailed
+		last.ExitCode = ve.ExitCode
+	}
+	return entries
+}
+
+// errorString returns err's message, or "" for nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
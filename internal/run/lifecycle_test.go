@@ -0,0 +1,127 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+func TestRunner_LifecycleEvents(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	tasksDir := filepath.Join(repoDir, ".turbine")
+	require.NoError(t, os.MkdirAll(tasksDir, 0755))
+	prdPath := filepath.Join(tasksDir, "prd.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("Test PRD"), 0644))
+	progressPath := filepath.Join(tasksDir, "progress.md")
+	require.NoError(t, os.WriteFile(progressPath, []byte("# Progress\n"), 0644))
+
+	marker := filepath.Join(t.TempDir(), "ok")
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			Verify:        []string{fmt.Sprintf("test -f %s", marker)},
+			CommitMessage: "feat: task 1",
+		},
+	}
+	taskPath := filepath.Join(tasksDir, "task.yaml")
+	require.NoError(t, taskFile.Save(taskPath))
+
+	var events bytes.Buffer
+	r := &Runner{
+		RepoRoot:     repoDir,
+		TaskFile:     taskFile,
+		State:        &state.RunState{RunID: "test-run"},
+		Config:       config.Defaults{Retry: config.Retry{Strokes: 1, Rotations: 2}},
+		PRDPath:      prdPath,
+		ProgressPath: progressPath,
+		Output:       Output{Quiet: true, JSON: true},
+		events:       &events,
+	}
+
+	calls := 0
+	mock := &mockProvider{runFunc: func(_ context.Context, params relay.RunParams, _ chan<- relay.Event) error {
+		calls++
+		switch calls {
+		case 1:
+			return nil // verification fails
+		case 2:
+			return os.WriteFile(marker, nil, 0644)
+		default:
+			done := &tasks.TaskFile{Version: 1, Task: tasks.Task{ID: "T-DONE", Title: "No remaining work", Status: tasks.StatusDone, Description: "All done."}}
+			return done.Save(filepath.Join(params.WorkingDir, TaskRelPath))
+		}
+	}}
+	require.NoError(t, r.Run(ctx, mock, Models{Fast: config.Model{Name: "fast-model"}}))
+
+	var got []LifecycleEvent
+	for _, line := range strings.Split(strings.TrimSpace(events.String()), "\n") {
+		var evt LifecycleEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &evt), "line %q", line)
+		assert.Equal(t, "test-run", evt.RunID)
+		assert.False(t, evt.Time.IsZero())
+		got = append(got, evt)
+	}
+
+	names := make([]string, 0, len(got))
+	for _, evt := range got {
+		names = append(names, evt.Event)
+	}
+	assert.Equal(t, []string{
+		EventRunStart,
+		EventTaskPlanned,
+		EventStrokeStart, EventVerifyResult, EventStrokeEnd,
+		EventRotationReset,
+		EventStrokeStart, EventVerifyResult, EventStrokeEnd,
+		EventCommit,
+		EventTaskEnd,
+		EventTaskPlanned,
+		EventRunEnd,
+	}, names)
+
+	assert.Equal(t, "mock", got[0].Backend)
+	assert.Equal(t, "fast-model", got[0].Model)
+	assert.Equal(t, "T1", got[1].TaskID)
+
+	failedVerify := got[3]
+	assert.Equal(t, StatusFailed, failedVerify.Status)
+	require.Len(t, failedVerify.Verify, 1)
+	assert.Equal(t, StatusFailed, failedVerify.Verify[0].Status)
+	assert.Equal(t, 1, failedVerify.Verify[0].ExitCode)
+	assert.Equal(t, ".turbine/runs/test-run/verify/01.log", failedVerify.Verify[0].Log)
+	assert.Equal(t, StatusFailed, got[4].Status)
+	assert.NotEmpty(t, got[4].Error)
+
+	assert.Equal(t, 2, got[5].Rotation)
+	assert.NotEmpty(t, got[5].Savepoint)
+
+	assert.Equal(t, StatusPassed, got[7].Status)
+	assert.Equal(t, StatusPassed, got[8].Status)
+	assert.NotEmpty(t, got[9].Commit)
+	assert.Equal(t, StatusDone, got[10].Status)
+	assert.Equal(t, "T-DONE", got[11].TaskID)
+	assert.Equal(t, StatusDone, got[12].Status)
+}
+
+func TestRunner_EmitDisabled(t *testing.T) {
+	var events bytes.Buffer
+	r := &Runner{State: &state.RunState{RunID: "test-run"}, events: &events}
+	r.emit(LifecycleEvent{Event: EventRunStart})
+	assert.Empty(t, events.String())
+}
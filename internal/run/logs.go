@@ -0,0 +1,235 @@
+package run
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	relaystore "github.com/yarlson/relay/store"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+// EventsFileName is the event log written for each workflow under .turbine/runs.
+const EventsFileName = "events.log"
+
+// WorkflowLog is the event log of one workflow (one task of a run).
+type WorkflowLog struct {
+	ID   string
+	Path string
+}
+
+// EventFilter selects events to show. Empty fields match everything.
+type EventFilter struct {
+	Task  string
+	Step  string
+	Kinds []string
+}
+
+// Match reports whether evt from workflowID passes the filter.
+func (f EventFilter) Match(workflowID string, evt relaystore.Event) bool {
+	if f.Task != "" && !strings.HasSuffix(workflowID, "-"+f.Task) {
+		return false
+	}
+	if f.Step != "" && evt.StepID != f.Step {
+		return false
+	}
+	if len(f.Kinds) > 0 {
+		for _, k := range f.Kinds {
+			if strings.EqualFold(k, string(evt.Kind)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// WorkflowLogs returns the event logs of a run's workflows, oldest first. id may be
+// a run ID or a single workflow ID. A compressed workflow's log is its archive.
+func WorkflowLogs(repoRoot, id string) ([]WorkflowLog, error) {
+	runsDir := filepath.Join(repoRoot, RunsDir)
+	entries, err := os.ReadDir(runsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read runs directory: %w", err)
+	}
+
+	var logs []WorkflowLog
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), compressedRunExt)
+		if name != id && !strings.HasPrefix(name, id+"-") {
+			continue
+		}
+		path := filepath.Join(runsDir, entry.Name())
+		switch {
+		case entry.IsDir():
+			path = filepath.Join(path, EventsFileName)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+		case name != entry.Name():
+			if _, err := archivedEvents(path); err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+		logs = append(logs, WorkflowLog{ID: name, Path: path})
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return modTime(logs[i].Path) < modTime(logs[j].Path)
+	})
+	return logs, nil
+}
+
+func modTime(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
+
+// ReadEvents decodes the complete events in path starting at byte offset and
+// returns them with the offset just past the last complete line, so a caller
+// can resume reading as the log grows. path may be a compressed workflow archive.
+func ReadEvents(path string, offset int64) ([]relaystore.Event, int64, error) {
+	var src io.Reader
+	if strings.HasSuffix(path, compressedRunExt) {
+		data, err := archivedEvents(path)
+		if err != nil {
+			return nil, offset, err
+		}
+		src = bytes.NewReader(data[min(offset, int64(len(data))):])
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, offset, err
+		}
+		defer func() { _ = f.Close() }()
+
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, offset, err
+		}
+		src = f
+	}
+
+	var events []relaystore.Event
+	reader := bufio.NewReader(src)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// An incomplete line is still being written; read it next time.
+			return events, offset, nil
+		}
+		if err != nil {
+			return events, offset, err
+		}
+		offset += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var evt relaystore.Event
+		if err := json.Unmarshal(line, &evt); err != nil {
+			continue
+		}
+		events = append(events, evt)
+	}
+}
+
+// archivedEvents returns the event log stored in a compressed workflow archive.
+func archivedEvents(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	want := strings.TrimSuffix(filepath.Base(path), compressedRunExt) + "/" + EventsFileName
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s has no %s: %w", filepath.Base(path), EventsFileName, fs.ErrNotExist)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+		}
+		if hdr.Name == want {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// FormatEvent renders evt as a transcript entry.
+func FormatEvent(evt relaystore.Event) string {
+	var b strings.Builder
+	prefix := ""
+	if !evt.Timestamp.IsZero() {
+		prefix = evt.Timestamp.Local().Format("15:04:05") + " "
+	}
+	if evt.StepID != "" {
+		prefix += "[" + evt.StepID + "] "
+	}
+	b.WriteString(ui.Dim(prefix))
+
+	kind := string(evt.Kind)
+	text := strings.TrimRight(evt.Text, "\n")
+	switch {
+	case evt.Error != "":
+		b.WriteString(ui.Red("error: " + evt.Error))
+		if text != "" {
+			b.WriteString("\n" + indentLines(text))
+		}
+	case strings.Contains(kind, "tool"):
+		b.WriteString(ui.Cyan("→ " + kind))
+		if text != "" {
+			b.WriteString(" " + ui.Dim(firstLine(text, "")))
+		}
+	case kind == "warning":
+		b.WriteString(ui.Yellow("⚠ " + text))
+	case text != "":
+		if kind != "" && kind != "text" {
+			b.WriteString(ui.Dim(kind + ": "))
+		}
+		lines := strings.SplitN(text, "\n", 2)
+		b.WriteString(lines[0])
+		if len(lines) > 1 {
+			b.WriteString("\n" + indentLines(lines[1]))
+		}
+	default:
+		b.WriteString(ui.Dim(kind))
+	}
+
+	if evt.Usage != nil {
+		b.WriteString(ui.Dim(fmt.Sprintf(" (usage: %d in, %d out, %.4f USD)", evt.Usage.InputTokens, evt.Usage.OutputTokens, evt.Usage.CostUSD)))
+	}
+	return b.String()
+}
+
+func indentLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = "    " + l
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,116 @@
+package run
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	relaystore "github.com/yarlson/relay/store"
+)
+
+func writeEvents(t *testing.T, path string, events ...relaystore.Event) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	for _, evt := range events {
+		data, err := json.Marshal(evt)
+		require.NoError(t, err)
+		_, err = f.Write(append(data, '\n'))
+		require.NoError(t, err)
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EventsFileName)
+	writeEvents(t, path,
+		relaystore.Event{Kind: "text", Text: "hello"},
+		relaystore.Event{Kind: "warning", Text: "careful"},
+	)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"kind": "te`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	events, offset, err := ReadEvents(path, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "hello", events[0].Text)
+
+	// Finish the partial line; only the new event is returned.
+	f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("xt\", \"text\": \"done\"}\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	events, _, err = ReadEvents(path, offset)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "done", events[0].Text)
+}
+
+func TestWorkflowLogs(t *testing.T) {
+	repoRoot := t.TempDir()
+	runsDir := filepath.Join(repoRoot, RunsDir)
+	writeEvents(t, filepath.Join(runsDir, "run-1-T-001", EventsFileName), relaystore.Event{Kind: "text"})
+	writeEvents(t, filepath.Join(runsDir, "run-1-T-002", EventsFileName), relaystore.Event{Kind: "text"})
+	writeEvents(t, filepath.Join(runsDir, "run-2-T-001", EventsFileName), relaystore.Event{Kind: "text"})
+	require.NoError(t, os.Chtimes(filepath.Join(runsDir, "run-1-T-001", EventsFileName), time.Now(), time.Now().Add(time.Hour)))
+
+	logs, err := WorkflowLogs(repoRoot, "run-1")
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "run-1-T-002", logs[0].ID)
+	assert.Equal(t, "run-1-T-001", logs[1].ID)
+
+	logs, err = WorkflowLogs(repoRoot, "run-2-T-001")
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	// Compressed workflows are read from their archives; archives without events are skipped.
+	require.NoError(t, os.MkdirAll(filepath.Join(runsDir, "run-2", SubDirGit), 0755))
+	_, err = compressRunDir(filepath.Join(runsDir, "run-2"))
+	require.NoError(t, err)
+	_, err = compressRunDir(filepath.Join(runsDir, "run-2-T-001"))
+	require.NoError(t, err)
+	logs, err = WorkflowLogs(repoRoot, "run-2")
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "run-2-T-001", logs[0].ID)
+	events, _, err := ReadEvents(logs[0].Path, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, relaystore.EventKind("text"), events[0].Kind)
+}
+
+func TestEventFilter(t *testing.T) {
+	evt := relaystore.Event{Kind: "text", StepID: "s0-0"}
+	assert.True(t, EventFilter{}.Match("run-1-T-001", evt))
+	assert.True(t, EventFilter{Task: "T-001", Step: "s0-0", Kinds: []string{"warning", "TEXT"}}.Match("run-1-T-001", evt))
+	assert.False(t, EventFilter{Task: "T-002"}.Match("run-1-T-001", evt))
+	assert.False(t, EventFilter{Step: "s0-1"}.Match("run-1-T-001", evt))
+	assert.False(t, EventFilter{Kinds: []string{"warning"}}.Match("run-1-T-001", evt))
+}
+
+func TestFormatEvent(t *testing.T) {
+	out := FormatEvent(relaystore.Event{Kind: "text", StepID: "s0-0", Text: "first\nsecond"})
+	assert.Contains(t, out, "[s0-0]")
+	assert.Contains(t, out, "first")
+	assert.Contains(t, out, "\n    second")
+
+	out = FormatEvent(relaystore.Event{Kind: "tool_use", Text: "Edit main.go\nmore"})
+	assert.Contains(t, out, "→ tool_use")
+	assert.Contains(t, out, "Edit main.go")
+	assert.NotContains(t, out, "more")
+
+	out = FormatEvent(relaystore.Event{Kind: "text", Error: "boom", Usage: &relaystore.Usage{InputTokens: 1, OutputTokens: 2, CostUSD: 0.5}})
+	assert.Contains(t, out, "error: boom")
+	assert.Contains(t, out, "1 in, 2 out, 0.5000 USD")
+}
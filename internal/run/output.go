@@ -0,0 +1,87 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/ui"
+)
+
+// streamIndent prefixes streamed agent output so it nests under the stroke line.
+const streamIndent = "    "
+
+// Output controls what a run prints to the terminal. Events are persisted under
+// .turbine/runs regardless.
+type Output struct {
+	Quiet   bool // Print only warnings and errors
+	Verbose bool // Stream agent text and tool activity during strokes
+	Debug   bool // Also stream every other event, with session and usage details
+	JSON    bool // Write lifecycle events as JSON lines on stdout; human output goes to stderr
+}
+
+// printf prints progress output unless the run is quiet.
+func (r *Runner) printf(format string, args ...any) {
+	if r.Output.Quiet {
+		return
+	}
+	fmt.Fprintf(r.Output.HumanWriter(), format, args...)
+}
+
+// warnf prints a warning, even when the run is quiet.
+func (r *Runner) warnf(format string, args ...any) {
+	fmt.Fprintf(r.Output.HumanWriter(), "  %s %s\n", ui.Yellow("⚠"), ui.Dim(fmt.Sprintf(format, args...)))
+}
+
+// streamEvent prints a backend event in verbose or debug mode.
+func (r *Runner) streamEvent(evt relay.Event) {
+	if r.Output.Quiet || !(r.Output.Verbose || r.Output.Debug) {
+		return
+	}
+	if line := formatStreamEvent(evt, r.Output.Debug); line != "" {
+		fmt.Fprintln(r.Output.HumanWriter(), line)
+	}
+}
+
+// formatStreamEvent renders evt compactly: agent text as indented lines, tool
+// activity as a single line, and (with debug) everything else with its details.
+func formatStreamEvent(evt relay.Event, debug bool) string {
+	kind := string(evt.Kind)
+	text := strings.TrimSpace(evt.Text)
+
+	switch {
+	case evt.Error != "":
+		return streamIndent + ui.Red("✗ "+evt.Error)
+	case strings.Contains(kind, "tool"):
+		return streamIndent + ui.Cyan("→ ") + ui.Dim(strings.TrimSpace(kind+" "+firstLine(text, "")))
+	case evt.Kind == relay.EventKindWarning:
+		return streamIndent + ui.Yellow("⚠ "+firstLine(text, kind))
+	case evt.Kind == relay.EventKindText && text != "":
+		var b strings.Builder
+		for i, line := range strings.Split(text, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if i > 0 && b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(streamIndent + ui.Dim("│ ") + line)
+		}
+		return b.String()
+	}
+
+	if !debug {
+		return ""
+	}
+	details := []string{kind}
+	if evt.SessionID != "" {
+		details = append(details, "session="+evt.SessionID)
+	}
+	if evt.Usage != nil {
+		details = append(details, fmt.Sprintf("tokens=%d/%d cost=%.4f", evt.Usage.InputTokens, evt.Usage.OutputTokens, evt.Usage.CostUSD))
+	}
+	if text != "" {
+		details = append(details, firstLine(text, ""))
+	}
+	return streamIndent + ui.Dim("· "+strings.Join(details, " "))
+}
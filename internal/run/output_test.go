@@ -0,0 +1,31 @@
+package run
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	relay "github.com/yarlson/relay"
+)
+
+func TestFormatStreamEvent(t *testing.T) {
+	out := formatStreamEvent(relay.Event{Kind: relay.EventKindText, Text: "Reading files\n\nEditing main.go\n"}, false)
+	assert.Contains(t, out, streamIndent)
+	assert.Contains(t, out, "Reading files")
+	assert.Contains(t, out, "Editing main.go")
+	assert.Len(t, strings.Split(out, "\n"), 2, "blank lines are dropped")
+
+	out = formatStreamEvent(relay.Event{Kind: "tool_use", Text: "Edit main.go\n+ line"}, false)
+	assert.Contains(t, out, "tool_use Edit main.go")
+	assert.NotContains(t, out, "+ line")
+
+	out = formatStreamEvent(relay.Event{Kind: relay.EventKindText, Error: "rate limited"}, false)
+	assert.Contains(t, out, "rate limited")
+
+	usage := relay.Event{Kind: "step_finish", SessionID: "sess-1", Usage: &relay.Usage{InputTokens: 5, OutputTokens: 7}}
+	assert.Empty(t, formatStreamEvent(usage, false), "other events only show in debug mode")
+	out = formatStreamEvent(usage, true)
+	assert.Contains(t, out, "step_finish")
+	assert.Contains(t, out, "session=sess-1")
+	assert.Contains(t, out, "tokens=5/7")
+}
@@ -0,0 +1,29 @@
+package run
+
+import (
+	"fmt"
+
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+)
+
+// ConfigOptions returns the options that load the config a run in repoRoot
+// uses. profile is the --profile flag; without it, an unfinished run keeps the
+// profile it was started with, including none.
+func ConfigOptions(repoRoot, profile string) (config.LoadOptions, error) {
+	if profile != "" || repoRoot == "" {
+		return config.LoadOptions{Profile: profile}, nil
+	}
+	st, exists, err := state.Load(repoRoot)
+	if err != nil {
+		return config.LoadOptions{}, fmt.Errorf("load state: %w", err)
+	}
+	if !exists || st.Profile == nil {
+		return config.LoadOptions{}, nil
+	}
+	return config.LoadOptions{
+		Profile:       *st.Profile,
+		ProfileSource: config.Source{Layer: config.LayerRun, Origin: st.RunID},
+		ProfileSet:    true,
+	}, nil
+}
@@ -14,6 +14,10 @@ const (
 	ProgressRelPath = ".turbine/progress.md"
 	ArchiveRelDir   = ".turbine/archive"
 	PRDRelPath      = ".turbine/prd.md"
+
+	// TaskBackupRelPath holds the previous version of task.yaml, used to recover
+	// from an interrupted write on resume.
+	TaskBackupRelPath = ".turbine/state/task.yaml.bak"
 )
 
 // EnsureProgressFile creates the progress file if it doesn't exist.
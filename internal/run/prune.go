@@ -0,0 +1,309 @@
+package run
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yarlson/turbine/internal/config"
+)
+
+const compressedRunExt = ".tar.gz"
+
+// runIDPattern matches the run ID prefix of a run or workflow directory name
+// (see GenerateRunID); workflow directories append "-<task ID>".
+var runIDPattern = regexp.MustCompile(`^\d{8}-\d{6}-[a-z0-9]{4}`)
+
+// PruneAction is what pruning does to a run.
+type PruneAction string
+
+const (
+	PruneKeep     PruneAction = "keep"
+	PruneCompress PruneAction = "compress"
+	PruneDelete   PruneAction = "delete"
+)
+
+// StoredRun groups the entries under .turbine/runs that belong to one run: its
+// artifact directory, its workflow directories and any compressed archives.
+type StoredRun struct {
+	ID       string
+	Paths    []string
+	Size     int64
+	Modified time.Time
+	Active   bool
+}
+
+// Compressed reports whether all of the run's entries are archives.
+func (r StoredRun) Compressed() bool {
+	if len(r.Paths) == 0 {
+		return false
+	}
+	for _, p := range r.Paths {
+		if !strings.HasSuffix(p, compressedRunExt) {
+			return false
+		}
+	}
+	return true
+}
+
+// PruneDecision is the planned action for one run.
+type PruneDecision struct {
+	Run    StoredRun
+	Action PruneAction
+	Reason string
+}
+
+// ListRuns returns the runs under .turbine/runs, newest first. The run activeRunID
+// is marked active.
+func ListRuns(repoRoot, activeRunID string) ([]StoredRun, error) {
+	runsDir := filepath.Join(repoRoot, RunsDir)
+	entries, err := os.ReadDir(runsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read runs directory: %w", err)
+	}
+
+	byID := map[string]*StoredRun{}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), compressedRunExt)
+		id := name
+		if m := runIDPattern.FindString(name); m != "" {
+			id = m
+		}
+		if activeRunID != "" && (name == activeRunID || strings.HasPrefix(name, activeRunID+"-")) {
+			id = activeRunID
+		}
+
+		path := filepath.Join(runsDir, entry.Name())
+		size, modified, err := treeStats(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r, ok := byID[id]
+		if !ok {
+			r = &StoredRun{ID: id, Active: id == activeRunID}
+			byID[id] = r
+		}
+		r.Paths = append(r.Paths, path)
+		r.Size += size
+		if modified.After(r.Modified) {
+			r.Modified = modified
+		}
+	}
+
+	runs := make([]StoredRun, 0, len(byID))
+	for _, r := range byID {
+		runs = append(runs, *r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].Modified.Equal(runs[j].Modified) {
+			return runs[i].Modified.After(runs[j].Modified)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	return runs, nil
+}
+
+// PlanPrune applies the retention policy to runs (newest first). Runs beyond
+// KeepRuns are compressed when Compress is set and deleted otherwise; runs older
+// than MaxAgeDays, or that would push the total over MaxSizeMB, are deleted. The
+// active run is always kept and counts toward the limits.
+func PlanPrune(runs []StoredRun, policy config.Retention, now time.Time) []PruneDecision {
+	decisions := make([]PruneDecision, 0, len(runs))
+	var total int64
+	maxSize := int64(policy.MaxSizeMB) << 20
+
+	for i, r := range runs {
+		d := PruneDecision{Run: r, Action: PruneKeep}
+		switch {
+		case r.Active:
+			d.Reason = "active run"
+		case policy.MaxAgeDays > 0 && now.Sub(r.Modified) > time.Duration(policy.MaxAgeDays)*24*time.Hour:
+			d.Action, d.Reason = PruneDelete, fmt.Sprintf("older than %d days", policy.MaxAgeDays)
+		case maxSize > 0 && total+r.Size > maxSize:
+			d.Action, d.Reason = PruneDelete, fmt.Sprintf("over %d MB total", policy.MaxSizeMB)
+		case policy.KeepRuns > 0 && i >= policy.KeepRuns:
+			if policy.Compress {
+				if !r.Compressed() {
+					d.Action, d.Reason = PruneCompress, fmt.Sprintf("beyond last %d runs", policy.KeepRuns)
+				}
+			} else {
+				d.Action, d.Reason = PruneDelete, fmt.Sprintf("beyond last %d runs", policy.KeepRuns)
+			}
+		}
+		if d.Action != PruneDelete {
+			total += r.Size
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+// ApplyPrune carries out the decisions and returns the number of bytes reclaimed.
+func ApplyPrune(decisions []PruneDecision) (int64, error) {
+	var reclaimed int64
+	for _, d := range decisions {
+		switch d.Action {
+		case PruneDelete:
+			for _, p := range d.Run.Paths {
+				if err := os.RemoveAll(p); err != nil {
+					return reclaimed, fmt.Errorf("delete %s: %w", p, err)
+				}
+			}
+			reclaimed += d.Run.Size
+		case PruneCompress:
+			for _, p := range d.Run.Paths {
+				if strings.HasSuffix(p, compressedRunExt) {
+					continue
+				}
+				saved, err := compressRunDir(p)
+				if err != nil {
+					return reclaimed, fmt.Errorf("compress %s: %w", p, err)
+				}
+				reclaimed += saved
+			}
+		}
+	}
+	return reclaimed, nil
+}
+
+// Prune applies policy to the runs of repoRoot, never touching activeRunID.
+func Prune(repoRoot, activeRunID string, policy config.Retention) ([]PruneDecision, int64, error) {
+	runs, err := ListRuns(repoRoot, activeRunID)
+	if err != nil {
+		return nil, 0, err
+	}
+	decisions := PlanPrune(runs, policy, time.Now())
+	reclaimed, err := ApplyPrune(decisions)
+	return decisions, reclaimed, err
+}
+
+// FormatBytes renders a byte count for humans.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// compressRunDir replaces dir with a gzip-compressed tarball next to it and
+// returns the bytes saved.
+func compressRunDir(dir string) (int64, error) {
+	before, modified, err := treeStats(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	archivePath := dir + compressedRunExt
+	tmp, err := os.CreateTemp(filepath.Dir(dir), "."+filepath.Base(archivePath)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	base := filepath.Dir(dir)
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if walkErr != nil {
+		_ = tmp.Close()
+		return 0, walkErr
+	}
+	if err := errors.Join(tw.Close(), gz.Close(), tmp.Sync(), tmp.Close()); err != nil {
+		return 0, err
+	}
+	// Keep the run's age so retention ordering is unchanged by compression.
+	if err := os.Chtimes(tmp.Name(), modified, modified); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), archivePath); err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return 0, err
+	}
+	return before - info.Size(), nil
+}
+
+// treeStats returns the total size of the regular files under path and the most
+// recent modification time.
+func treeStats(path string) (int64, time.Time, error) {
+	var size int64
+	var modified time.Time
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("scan %s: %w", path, err)
+	}
+	return size, modified, nil
+}
@@ -0,0 +1,109 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yarlson/turbine/internal/config"
+)
+
+// writeRunDir creates a run directory holding size bytes, last modified at modified.
+func writeRunDir(t *testing.T, repoRoot, name string, size int, modified time.Time) string {
+	t.Helper()
+	dir := filepath.Join(repoRoot, RunsDir, name)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, SubDirVerify), 0755))
+	file := filepath.Join(dir, SubDirVerify, "01.log")
+	require.NoError(t, os.WriteFile(file, []byte(strings.Repeat("x", size)), 0644))
+	for _, p := range []string{file, filepath.Join(dir, SubDirVerify), dir} {
+		require.NoError(t, os.Chtimes(p, modified, modified))
+	}
+	return dir
+}
+
+func TestListRuns(t *testing.T) {
+	repoRoot := t.TempDir()
+	now := time.Now()
+	writeRunDir(t, repoRoot, "20260101-100000-aaaa", 10, now.Add(-2*time.Hour))
+	writeRunDir(t, repoRoot, "20260101-100000-aaaa-T-001", 20, now.Add(-time.Hour))
+	writeRunDir(t, repoRoot, "20260102-100000-bbbb", 5, now)
+
+	runs, err := ListRuns(repoRoot, "20260102-100000-bbbb")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "20260102-100000-bbbb", runs[0].ID)
+	assert.True(t, runs[0].Active)
+	assert.Equal(t, "20260101-100000-aaaa", runs[1].ID)
+	assert.Len(t, runs[1].Paths, 2)
+	assert.Equal(t, int64(30), runs[1].Size)
+}
+
+func TestPlanPrune(t *testing.T) {
+	now := time.Now()
+	runs := []StoredRun{
+		{ID: "r4", Size: 10 << 20, Modified: now},
+		{ID: "r3", Size: 10 << 20, Modified: now.Add(-24 * time.Hour), Active: true},
+		{ID: "r2", Size: 10 << 20, Modified: now.Add(-48 * time.Hour)},
+		{ID: "r1", Size: 10 << 20, Modified: now.Add(-30 * 24 * time.Hour)},
+	}
+	actions := func(decisions []PruneDecision) []PruneAction {
+		var out []PruneAction
+		for _, d := range decisions {
+			out = append(out, d.Action)
+		}
+		return out
+	}
+
+	assert.Equal(t, []PruneAction{PruneKeep, PruneKeep, PruneDelete, PruneDelete},
+		actions(PlanPrune(runs, config.Retention{KeepRuns: 2}, now)))
+	assert.Equal(t, []PruneAction{PruneKeep, PruneKeep, PruneCompress, PruneCompress},
+		actions(PlanPrune(runs, config.Retention{KeepRuns: 1, Compress: true}, now)), "active run is never pruned")
+	assert.Equal(t, []PruneAction{PruneKeep, PruneKeep, PruneKeep, PruneDelete},
+		actions(PlanPrune(runs, config.Retention{MaxAgeDays: 7}, now)))
+	assert.Equal(t, []PruneAction{PruneKeep, PruneKeep, PruneDelete, PruneDelete},
+		actions(PlanPrune(runs, config.Retention{MaxSizeMB: 25}, now)))
+}
+
+func TestPrune(t *testing.T) {
+	repoRoot := t.TempDir()
+	now := time.Now()
+	active := writeRunDir(t, repoRoot, "20260101-100000-aaaa", 100, now.Add(-72*time.Hour))
+	old := writeRunDir(t, repoRoot, "20260102-100000-bbbb", 4096, now.Add(-48*time.Hour))
+	recent := writeRunDir(t, repoRoot, "20260103-100000-cccc", 100, now)
+
+	t.Run("compress", func(t *testing.T) {
+		decisions, reclaimed, err := Prune(repoRoot, "20260101-100000-aaaa", config.Retention{KeepRuns: 1, Compress: true})
+		require.NoError(t, err)
+		assert.Len(t, decisions, 3)
+		assert.Positive(t, reclaimed)
+
+		assert.DirExists(t, active)
+		assert.DirExists(t, recent)
+		assert.NoDirExists(t, old)
+		assert.FileExists(t, old+compressedRunExt)
+
+		// Already compressed runs are left alone.
+		_, reclaimed, err = Prune(repoRoot, "20260101-100000-aaaa", config.Retention{KeepRuns: 1, Compress: true})
+		require.NoError(t, err)
+		assert.Zero(t, reclaimed)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, reclaimed, err := Prune(repoRoot, "20260101-100000-aaaa", config.Retention{KeepRuns: 1})
+		require.NoError(t, err)
+		assert.Positive(t, reclaimed)
+		assert.NoFileExists(t, old+compressedRunExt)
+		assert.DirExists(t, active)
+		assert.DirExists(t, recent)
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "10.0 MiB", FormatBytes(10<<20))
+}
@@ -2,8 +2,11 @@ package run
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/yarlson/turbine/internal/config"
 	"github.com/yarlson/turbine/internal/gitx"
 	"github.com/yarlson/turbine/internal/state"
 	"github.com/yarlson/turbine/internal/tasks"
@@ -14,6 +17,14 @@ import (
 type RetryPolicy struct {
 	MaxStrokes   int
 	MaxRotations int
+	// Choose, if set, picks the backend and model of each stroke before it starts.
+	Choose func() (StrokeChoice, error)
+	// Backoff spaces out retries of a stroke that failed with a transient backend
+	// error. These retries do not use up strokes.
+	Backoff config.Backoff
+	// Failover, if set, switches backends after a backend error and reports
+	// whether it did; the stroke is then retried on the new backend.
+	Failover func(err *BackendError) bool
 }
 
 func (p *RetryPolicy) Execute(ctx context.Context, r *Runner, task *tasks.Task, execute func(ctx context.Context) error) error {
@@ -22,6 +33,7 @@ func (p *RetryPolicy) Execute(ctx context.Context, r *Runner, task *tasks.Task,
 		r.State.ActiveTaskID = task.ID
 		r.State.Rotation = 1
 		r.State.Stroke = 1
+		r.State.BackendSessionID = ""
 		// Store last save point if not already set
 		if r.State.LastSavepointCommit == "" {
 			hash, err := gitx.CurrentHash(ctx, r.RepoRoot)
@@ -35,17 +47,61 @@ func (p *RetryPolicy) Execute(ctx context.Context, r *Runner, task *tasks.Task,
 		}
 	}
 
+	// Transient retries of the current stroke
+	retries := 0
+
 	for r.State.Rotation <= p.MaxRotations {
 		for r.State.Stroke <= p.MaxStrokes {
-			fmt.Printf("  %s Stroke %d/%d (rotation %d)\n", ui.InProgressMarker(), r.State.Stroke, p.MaxStrokes, r.State.Rotation)
+			var choice StrokeChoice
+			if p.Choose != nil {
+				var err error
+				if choice, err = p.Choose(); err != nil {
+					return err
+				}
+			}
+			r.printf("  %s Stroke %d/%d (rotation %d%s)\n", ui.InProgressMarker(), r.State.Stroke, p.MaxStrokes, r.State.Rotation, choice.label())
 
+			if err := r.checkBudget(ctx); err != nil {
+				return err
+			}
+			startEvt := LifecycleEvent{Event: EventStrokeStart, TaskID: task.ID, Rotation: r.State.Rotation, Stroke: r.State.Stroke, Reason: choice.Reason}
+			if choice.Provider != nil {
+				startEvt.Backend, startEvt.Model, startEvt.Variant = choice.Provider.Name(), choice.Model.Name, choice.Model.Variant
+			}
+			r.emit(startEvt)
 			err := execute(ctx)
 			if err == nil {
-				// Success!
+				r.emit(LifecycleEvent{Event: EventStrokeEnd, TaskID: task.ID, Rotation: r.State.Rotation, Stroke: r.State.Stroke, Status: StatusPassed})
 				return nil
 			}
+			r.emit(LifecycleEvent{Event: EventStrokeEnd, TaskID: task.ID, Rotation: r.State.Rotation, Stroke: r.State.Stroke, Status: StatusFailed, Error: err.Error()})
+
+			r.printf("  %s %v\n", ui.FailureMarker(), ui.Dim(fmt.Sprintf("Stroke failed: %v", err)))
 
-			fmt.Printf("  %s %v\n", ui.FailureMarker(), ui.Dim(fmt.Sprintf("Stroke failed: %v", err)))
+			var backendErr *BackendError
+			if errors.As(err, &backendErr) {
+				if backendErr.Class.Transient() && retries < p.Backoff.MaxAttempts() {
+					retries++
+					delay := p.Backoff.Delay(retries)
+					r.printf("  %s %s\n", ui.Yellow("⟳"), ui.Dim(fmt.Sprintf("Backend %s; retrying in %s (%d/%d)", backendErr.Class, delay, retries, p.Backoff.MaxAttempts())))
+					if err := sleep(ctx, delay); err != nil {
+						return err
+					}
+					continue
+				}
+				if (backendErr.Class.Transient() || backendErr.Class.Fatal()) && p.Failover != nil && p.Failover(backendErr) {
+					retries = 0
+					continue
+				}
+				if backendErr.Class.Fatal() {
+					return backendErr
+				}
+				if backendErr.Class == ErrorContextTooLong {
+					// Continuing the session cannot help; the next stroke starts a new one.
+					r.State.BackendSessionID = ""
+				}
+			}
+			retries = 0
 
 			if r.State.Stroke < p.MaxStrokes {
 				r.State.Stroke++
@@ -60,7 +116,7 @@ func (p *RetryPolicy) Execute(ctx context.Context, r *Runner, task *tasks.Task,
 		// Rotation exhausted, increment rotation and reset
 		if r.State.Rotation < p.MaxRotations {
 			r.State.Rotation++
-			fmt.Printf("  %s Rotation %d failed. Re-spinning at %s\n", ui.Yellow("⟳"), r.State.Rotation-1, ui.Dim(r.State.LastSavepointCommit[:8]))
+			r.printf("  %s Rotation %d failed. Re-spinning at %s\n", ui.Yellow("⟳"), r.State.Rotation-1, ui.Dim(r.State.LastSavepointCommit[:8]))
 			if err := gitx.ResetHard(ctx, r.RepoRoot, r.State.LastSavepointCommit); err != nil {
 				return fmt.Errorf("reset to savepoint: %w", err)
 			}
@@ -69,6 +125,7 @@ func (p *RetryPolicy) Execute(ctx context.Context, r *Runner, task *tasks.Task,
 			if err := state.Save(r.RepoRoot, r.State); err != nil {
 				return err
 			}
+			r.emit(LifecycleEvent{Event: EventRotationReset, TaskID: task.ID, Rotation: r.State.Rotation, Savepoint: r.State.LastSavepointCommit})
 		} else {
 			break
 		}
@@ -78,3 +135,15 @@ func (p *RetryPolicy) Execute(ctx context.Context, r *Runner, task *tasks.Task,
 	task.Status = tasks.StatusFailed
 	return fmt.Errorf("%s failed after %d rotations", task.ID, p.MaxRotations)
 }
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
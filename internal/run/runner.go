@@ -3,14 +3,17 @@ package run
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
 	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/atomicfile"
 	"github.com/yarlson/turbine/internal/config"
 	"github.com/yarlson/turbine/internal/decomposer"
 	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/notify"
 	"github.com/yarlson/turbine/internal/state"
 	"github.com/yarlson/turbine/internal/tasks"
 	"github.com/yarlson/turbine/internal/ui"
@@ -24,12 +27,26 @@ type Runner struct {
 	Resume       bool
 	PRDPath      string
 	ProgressPath string
+	Output       Output
+
+	lock   *state.Lock
+	events io.Writer // Lifecycle event destination in JSON mode; stdout when nil
+
+	models    Models                   // The run's models, set by Run
+	backends  BackendFactory           // Creates escalation and failover backends
+	providers map[string]cachedBackend // Backends created by backends
 }
 
 type Config struct {
 	AutoAddIgnore bool
 	Cwd           string
 	Defaults      config.Defaults
+	Output        Output
+	// Profile is the applied config profile, recorded in the run state.
+	Profile string
+	// Backends creates the other backends named by the escalation ladder and
+	// failover list. Without it every stroke uses the run's backend.
+	Backends BackendFactory
 }
 
 type Models struct {
@@ -37,7 +54,9 @@ type Models struct {
 	Slow config.Model
 }
 
-func NewRunner(ctx context.Context, cfg Config) (*Runner, error) {
+// NewRunner prepares a run in the repository containing cfg.Cwd. It holds the run lock
+// until Close is called, so only one Turbine process works on a repository at a time.
+func NewRunner(ctx context.Context, cfg Config) (_ *Runner, err error) {
 	cwd := cfg.Cwd
 	if cwd == "" {
 		var err error
@@ -52,6 +71,16 @@ func NewRunner(ctx context.Context, cfg Config) (*Runner, error) {
 		return nil, fmt.Errorf("determine repo root: %w", err)
 	}
 
+	lock, err := state.AcquireLock(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("acquire run lock: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = lock.Release()
+		}
+	}()
+
 	prdPath := filepath.Join(repoRoot, PRDRelPath)
 	if _, err := os.Stat(prdPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("PRD file not found: %s", prdPath)
@@ -72,11 +101,13 @@ func NewRunner(ctx context.Context, cfg Config) (*Runner, error) {
 			RunID: GenerateRunID(),
 		}
 	}
+	runState.Profile = &cfg.Profile
 
 	// Clean working tree rule
 	// IMPORTANT: Check this BEFORE we modify .gitignore ourselves
 	if !exists {
-		dirty, err := gitx.IsDirty(ctx, repoRoot)
+		// Our own lock file is not a user change.
+		dirty, err := gitx.IsDirtyExcluding(ctx, repoRoot, state.LockRelPath)
 		if err != nil {
 			return nil, fmt.Errorf("check repository status: %w", err)
 		}
@@ -97,11 +128,12 @@ func NewRunner(ctx context.Context, cfg Config) (*Runner, error) {
 				return nil, fmt.Errorf("update .gitignore: %w", err)
 			}
 		} else {
-			fmt.Printf("%s\n", ui.Section("⚠", "Not in .gitignore"))
+			w := cfg.Output.HumanWriter()
+			fmt.Fprintf(w, "%s\n", ui.Section("⚠", "Not in .gitignore"))
 			for _, m := range missing {
-				fmt.Printf("  %s\n", ui.Dim(m))
+				fmt.Fprintf(w, "  %s\n", ui.Dim(m))
 			}
-			fmt.Printf("%s\n", ui.Dim("Use --yes to add automatically."))
+			fmt.Fprintf(w, "%s\n", ui.Dim("Use --yes to add automatically."))
 		}
 	}
 
@@ -116,30 +148,66 @@ func NewRunner(ctx context.Context, cfg Config) (*Runner, error) {
 		Resume:       exists,
 		PRDPath:      prdPath,
 		ProgressPath: progressPath,
+		Output:       cfg.Output,
+		lock:         lock,
+		backends:     cfg.Backends,
 	}, nil
 }
 
+// Close releases the run lock.
+func (r *Runner) Close() error {
+	return r.lock.Release()
+}
+
 func (r *Runner) PrintSummary() {
 	if r.TaskFile == nil {
-		fmt.Printf("\n%s\n", ui.Divider(40))
-		fmt.Printf("%s\n", ui.Dim("No active task loaded."))
+		r.printf("\n%s\n", ui.Divider(40))
+		r.printf("%s\n", ui.Dim("No active task loaded."))
 		return
 	}
 
-	fmt.Printf("\n%s\n", ui.Divider(40))
-	fmt.Printf("task: %s %s (%s)\n", r.TaskFile.Task.ID, r.TaskFile.Task.Title, r.TaskFile.Task.Status)
+	r.printf("\n%s\n", ui.Divider(40))
+	r.printf("task: %s %s (%s)\n", r.TaskFile.Task.ID, r.TaskFile.Task.Title, r.TaskFile.Task.Status)
 }
 
 // Run plans and executes tasks sequentially using the provided backend and models.
-func (r *Runner) Run(ctx context.Context, backend relay.Provider, models Models) error {
+func (r *Runner) Run(ctx context.Context, backend relay.Provider, models Models) (err error) {
 	taskPath := filepath.Join(r.RepoRoot, TaskRelPath)
+	r.models = models
+
+	r.emit(LifecycleEvent{
+		Event:   EventRunStart,
+		Backend: backend.Name(),
+		Model:   models.Fast.Name,
+		Variant: models.Fast.Variant,
+		Resumed: r.Resume,
+	})
+	defer func() {
+		status := StatusDone
+		if err != nil {
+			status = StatusFailed
+		}
+		r.emit(LifecycleEvent{Event: EventRunEnd, Status: status, Error: errorString(err)})
+		r.notify(ctx, notify.Event{Event: notify.EventRunFinished, Status: status, Error: errorString(err)})
+	}()
 
 	for {
+		if err := r.checkBudget(ctx); err != nil {
+			return err
+		}
+		resumed := r.Resume && r.State.ActiveTaskID != ""
 		taskFile, err := r.loadOrPlanTask(ctx, backend, models, taskPath)
 		if err != nil {
 			return err
 		}
 		r.TaskFile = taskFile
+		r.emit(LifecycleEvent{
+			Event:   EventTaskPlanned,
+			TaskID:  taskFile.Task.ID,
+			Title:   taskFile.Task.Title,
+			Status:  string(taskFile.Task.Status),
+			Resumed: resumed,
+		})
 
 		if taskFile.Task.Status == tasks.StatusDone {
 			entry := fmt.Sprintf("- %s %s %s - done (no remaining work)", timeNowUTC(), taskFile.Task.ID, taskFile.Task.Title)
@@ -149,7 +217,7 @@ func (r *Runner) Run(ctx context.Context, backend relay.Provider, models Models)
 			if _, err := ArchiveTaskFile(r.RepoRoot, taskFile); err != nil {
 				return err
 			}
-			_ = os.Remove(taskPath)
+			_ = atomicfile.Remove(taskPath, filepath.Join(r.RepoRoot, TaskBackupRelPath))
 			break
 		}
 
@@ -169,7 +237,7 @@ func (r *Runner) Run(ctx context.Context, backend relay.Provider, models Models)
 
 func (r *Runner) loadOrPlanTask(ctx context.Context, backend relay.Provider, models Models, taskPath string) (*tasks.TaskFile, error) {
 	if r.Resume && r.State.ActiveTaskID != "" {
-		return tasks.LoadTaskFile(taskPath)
+		return tasks.LoadTaskFileWithBackup(taskPath, filepath.Join(r.RepoRoot, TaskBackupRelPath))
 	}
 
 	if _, err := os.Stat(taskPath); err == nil {
@@ -178,8 +246,15 @@ func (r *Runner) loadOrPlanTask(ctx context.Context, backend relay.Provider, mod
 		return nil, fmt.Errorf("stat task file: %w", err)
 	}
 
+	if err := r.runHooks(ctx, HookPrePlan, r.Config.Hooks.PrePlan, hookContext{}); err != nil {
+		return nil, err
+	}
+
 	planner := decomposer.New(backend, r.RepoRoot)
-	if err := planner.PlanNext(ctx, r.PRDPath, r.ProgressPath, PlanOptionsFromModels(models)); err != nil {
+	opts := PlanOptionsFromModels(models)
+	opts.MessagePolicy = messagePolicy(r.Config.Commit.Message)
+	opts.OnEvent = r.addUsage
+	if err := planner.PlanNext(ctx, r.PRDPath, r.ProgressPath, opts); err != nil {
 		return nil, err
 	}
 
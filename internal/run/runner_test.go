@@ -101,9 +101,11 @@ func TestRunner_Preflight(t *testing.T) {
 		err = os.WriteFile(filepath.Join(repoDir, "dirty.txt"), []byte("dirty"), 0644)
 		require.NoError(t, err)
 
-		runner, err := NewRunner(ctx, Config{Cwd: repoDir})
+		runner, err := NewRunner(ctx, Config{Cwd: repoDir, Profile: "cheap"})
 		assert.NoError(t, err)
 		assert.True(t, runner.Resume)
+		require.NotNil(t, runner.State.Profile)
+		assert.Equal(t, "cheap", *runner.State.Profile, "the profile is recorded for the next resume")
 	})
 
 	t.Run("ignore-missing detection and auto-add", func(t *testing.T) {
@@ -146,6 +148,29 @@ func TestRunner_Preflight(t *testing.T) {
 	})
 }
 
+func TestRunner_Lock(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".git", "info", "exclude"), []byte(".turbine/\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".turbine", "prd.md"), []byte("Test PRD"), 0644))
+
+	runner, err := NewRunner(ctx, Config{Cwd: repoDir})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(repoDir, state.LockRelPath))
+
+	_, err = NewRunner(ctx, Config{Cwd: repoDir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "another turbine process is running")
+
+	require.NoError(t, runner.Close())
+	assert.NoFileExists(t, filepath.Join(repoDir, state.LockRelPath))
+
+	runner, err = NewRunner(ctx, Config{Cwd: repoDir})
+	require.NoError(t, err)
+	require.NoError(t, runner.Close())
+}
+
 func TestRunner_PrintSummary(t *testing.T) {
 	taskFile := &tasks.TaskFile{
 		Version: 1,
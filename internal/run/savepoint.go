@@ -0,0 +1,164 @@
+package run
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/tasks"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// NotesRef is the git notes namespace holding Turbine savepoint metadata.
+	NotesRef = "refs/notes/turbine"
+
+	// TrailerTask identifies a Turbine savepoint commit and its task ID.
+	TrailerTask = "Turbine"
+	// TrailerRun records the run that produced a savepoint commit.
+	TrailerRun = "Turbine-Run"
+	// TrailerTasks lists every task ID of a squashed commit, comma separated;
+	// TrailerTask then names its last task.
+	TrailerTasks = "Turbine-Tasks"
+	// TrailerReverts marks a commit made by turbine undo with the hash of the
+	// savepoint commit it reverts.
+	TrailerReverts = "Turbine-Reverts"
+)
+
+// usageTotals accumulates token usage reported by backend events.
+type usageTotals struct {
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+func (u *usageTotals) add(evt relay.Event) {
+	if evt.Usage == nil {
+		return
+	}
+	u.InputTokens += evt.Usage.InputTokens
+	u.OutputTokens += evt.Usage.OutputTokens
+	u.CostUSD += evt.Usage.CostUSD
+}
+
+// savepointMeta describes how a savepoint commit was produced.
+type savepointMeta struct {
+	TaskID   string
+	RunID    string
+	Backend  string
+	Model    string
+	Variant  string
+	Rotation int
+	Stroke   int
+	Usage    usageTotals
+}
+
+// commitTrailers returns the trailer lines for a savepoint commit.
+// The "Turbine:" footer always comes first; keys selects the optional trailers in order.
+func commitTrailers(keys []string, meta savepointMeta) []string {
+	lines := []string{fmt.Sprintf("%s: %s", TrailerTask, meta.TaskID)}
+	for _, key := range keys {
+		switch key {
+		case "run":
+			lines = append(lines, fmt.Sprintf("%s: %s", TrailerRun, meta.RunID))
+		case "backend":
+			lines = append(lines, fmt.Sprintf("Turbine-Backend: %s", meta.Backend))
+		case "model":
+			model := meta.Model
+			if meta.Variant != "" {
+				model = fmt.Sprintf("%s (%s)", model, meta.Variant)
+			}
+			lines = append(lines, fmt.Sprintf("Turbine-Model: %s", model))
+		case "rotation":
+			lines = append(lines, fmt.Sprintf("Turbine-Rotation: %d", meta.Rotation))
+		case "stroke":
+			lines = append(lines, fmt.Sprintf("Turbine-Stroke: %d", meta.Stroke))
+		case "tokens":
+			lines = append(lines, fmt.Sprintf("Turbine-Tokens: %d in, %d out", meta.Usage.InputTokens, meta.Usage.OutputTokens))
+		case "cost":
+			lines = append(lines, fmt.Sprintf("Turbine-Cost: %.4f USD", meta.Usage.CostUSD))
+		}
+	}
+	return lines
+}
+
+// messagePolicy converts the configured commit message policy.
+func messagePolicy(cfg config.CommitMessage) gitx.MessagePolicy {
+	return gitx.MessagePolicy{
+		Conventional: cfg.Conventional,
+		Types:        cfg.Types,
+		Scopes:       cfg.Scopes,
+		MaxSubject:   cfg.MaxSubject,
+	}
+}
+
+// commitOptions builds the git options for a savepoint commit of task.
+func commitOptions(cfg config.Commit, task tasks.Task, trailers []string) gitx.CommitOptions {
+	opts := gitx.CommitOptions{
+		Trailers:   trailers,
+		Author:     gitx.Identity{Name: cfg.Author.Name, Email: cfg.Author.Email},
+		Committer:  gitx.Identity{Name: cfg.Committer.Name, Email: cfg.Committer.Email},
+		Sign:       cfg.Sign,
+		SigningKey: cfg.SigningKey,
+	}
+	if cfg.Message.Body {
+		opts.Body = commitBody(task)
+	}
+	return opts
+}
+
+// commitBody renders the task description and acceptance criteria as a commit body.
+func commitBody(task tasks.Task) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(task.Description))
+	if len(task.Acceptance) > 0 {
+		b.WriteString("\n\nAcceptance criteria:\n")
+		for _, a := range task.Acceptance {
+			fmt.Fprintf(&b, "- %s\n", a)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+type commitNote struct {
+	Run          string            `yaml:"run"`
+	Task         *tasks.TaskFile   `yaml:"task"`
+	Verification []verifyNoteEntry `yaml:"verification"`
+}
+
+type verifyNoteEntry struct {
+	Command  string `yaml:"command"`
+	Duration string `yaml:"duration"`
+	Log      string `yaml:"log"`
+}
+
+// buildCommitNote renders the git note body for a savepoint commit: the full
+// task file plus a summary of the verification commands that passed.
+func buildCommitNote(repoRoot, runID string, taskFile *tasks.TaskFile, results []VerifyResult) (string, error) {
+	note := commitNote{
+		Run:          runID,
+		Task:         taskFile,
+		Verification: make([]verifyNoteEntry, 0, len(results)),
+	}
+	for _, res := range results {
+		logPath := res.LogPath
+		if rel, err := filepath.Rel(repoRoot, res.LogPath); err == nil {
+			logPath = filepath.ToSlash(rel)
+		}
+		note.Verification = append(note.Verification, verifyNoteEntry{
+			Command:  res.Command,
+			Duration: res.Duration.Round(time.Millisecond).String(),
+			Log:      logPath,
+		})
+	}
+
+	data, err := yaml.Marshal(note)
+	if err != nil {
+		return "", fmt.Errorf("marshal commit note: %w", err)
+	}
+	return string(data), nil
+}
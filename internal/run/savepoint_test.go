@@ -0,0 +1,111 @@
+package run
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/tasks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTotals_Add(t *testing.T) {
+	var u usageTotals
+	u.add(relay.Event{Kind: relay.EventKindText, Text: "no usage"})
+	u.add(relay.Event{Usage: &relay.Usage{InputTokens: 10, OutputTokens: 5, CostUSD: 0.25}})
+	u.add(relay.Event{Usage: &relay.Usage{InputTokens: 1, OutputTokens: 2, CostUSD: 0.5}})
+
+	assert.Equal(t, usageTotals{InputTokens: 11, OutputTokens: 7, CostUSD: 0.75}, u)
+}
+
+func TestCommitTrailers(t *testing.T) {
+	meta := savepointMeta{
+		TaskID:   "T-001",
+		RunID:    "run-1",
+		Backend:  "claude",
+		Model:    "sonnet",
+		Variant:  "high",
+		Rotation: 2,
+		Stroke:   3,
+		Usage:    usageTotals{InputTokens: 100, OutputTokens: 50, CostUSD: 0.125},
+	}
+
+	t.Run("footer only", func(t *testing.T) {
+		assert.Equal(t, []string{"Turbine: T-001"}, commitTrailers(nil, meta))
+	})
+
+	t.Run("all trailers in configured order", func(t *testing.T) {
+		lines := commitTrailers([]string{"cost", "run", "backend", "model", "rotation", "stroke", "tokens"}, meta)
+		assert.Equal(t, []string{
+			"Turbine: T-001",
+			"Turbine-Cost: 0.1250 USD",
+			"Turbine-Run: run-1",
+			"Turbine-Backend: claude",
+			"Turbine-Model: sonnet (high)",
+			"Turbine-Rotation: 2",
+			"Turbine-Stroke: 3",
+			"Turbine-Tokens: 100 in, 50 out",
+		}, lines)
+	})
+
+	t.Run("unknown keys ignored", func(t *testing.T) {
+		assert.Equal(t, []string{"Turbine: T-001"}, commitTrailers([]string{"bogus"}, meta))
+	})
+}
+
+func TestBuildCommitNote(t *testing.T) {
+	repoRoot := t.TempDir()
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T-001",
+			Title:         "Task 1",
+			Status:        tasks.StatusDone,
+			Description:   "Description 1",
+			Verify:        []string{"go test ./..."},
+			CommitMessage: "feat: task 1",
+		},
+	}
+	results := []VerifyResult{{
+		Command:  "go test ./...",
+		Duration: 1500 * time.Millisecond,
+		LogPath:  filepath.Join(repoRoot, RunsDir, "run-1", SubDirVerify, "01.log"),
+	}}
+
+	note, err := buildCommitNote(repoRoot, "run-1", taskFile, results)
+	require.NoError(t, err)
+
+	assert.Contains(t, note, "run: run-1")
+	assert.Contains(t, note, "id: T-001")
+	assert.Contains(t, note, "commit_message: 'feat: task 1'")
+	assert.Contains(t, note, "command: go test ./...")
+	assert.Contains(t, note, "duration: 1.5s")
+	assert.Contains(t, note, "log: .turbine/runs/run-1/verify/01.log")
+}
+
+func TestCommitOptions(t *testing.T) {
+	task := tasks.Task{
+		ID:          "T-001",
+		Description: "Add the widget.\n",
+		Acceptance:  []string{"Widget renders", "Widget is tested"},
+	}
+	cfg := config.Commit{
+		Author:     config.Identity{Name: "Bot", Email: "bot@example.com"},
+		Sign:       "ssh",
+		SigningKey: "~/.ssh/id_ed25519.pub",
+	}
+
+	opts := commitOptions(cfg, task, []string{"Turbine: T-001"})
+	assert.Empty(t, opts.Body)
+	assert.Equal(t, "Bot", opts.Author.Name)
+	assert.Equal(t, "ssh", opts.Sign)
+	assert.Equal(t, []string{"Turbine: T-001"}, opts.Trailers)
+
+	cfg.Message.Body = true
+	opts = commitOptions(cfg, task, nil)
+	assert.Equal(t, "Add the widget.\n\nAcceptance criteria:\n- Widget renders\n- Widget is tested", opts.Body)
+}
@@ -0,0 +1,79 @@
+package run
+
+import (
+	"context"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/state"
+)
+
+// sessionProvider continues a known backend session on its first Run instead of
+// starting a new one. If the backend cannot resume the session before producing
+// any output, it falls back to a fresh session so the task can continue.
+type sessionProvider struct {
+	relay.Provider
+	sessionID  string
+	onFallback func(err error)
+}
+
+func (p *sessionProvider) Run(ctx context.Context, params relay.RunParams, events chan<- relay.Event) error {
+	sessionID := p.sessionID
+	p.sessionID = ""
+	if sessionID == "" {
+		return p.Provider.Run(ctx, params, events)
+	}
+
+	inner := make(chan relay.Event)
+	done := make(chan struct{})
+	forwarded := false
+	go func() {
+		defer close(done)
+		for evt := range inner {
+			forwarded = true
+			events <- evt
+		}
+	}()
+
+	err := p.Provider.Resume(ctx, sessionID, params, inner)
+	<-done
+	if err == nil || forwarded || ctx.Err() != nil {
+		close(events)
+		return err
+	}
+
+	if p.onFallback != nil {
+		p.onFallback(err)
+	}
+	return p.Provider.Run(ctx, params, events)
+}
+
+// strokeProvider returns the provider for the next stroke. A session recorded for
+// the same backend is resumed: either an earlier stroke of this rotation or the
+// stroke that was interrupted before a restart. Rotations start without one.
+func (r *Runner) strokeProvider(backend relay.Provider) relay.Provider {
+	sessionID := r.State.BackendSessionID
+	if sessionID == "" || r.State.BackendName != backend.Name() {
+		return backend
+	}
+	return &sessionProvider{
+		Provider:  backend,
+		sessionID: sessionID,
+		onFallback: func(err error) {
+			r.warnf("Could not resume session %s (%v); starting a new session", sessionID, err)
+			r.State.BackendSessionID = ""
+		},
+	}
+}
+
+// recordSession persists the backend session reported by evt so a restarted run
+// can continue it.
+func (r *Runner) recordSession(backendName string, evt relay.Event) {
+	if evt.SessionID == "" || (evt.SessionID == r.State.BackendSessionID && backendName == r.State.BackendName) {
+		return
+	}
+	r.State.BackendName = backendName
+	r.State.BackendSessionID = evt.SessionID
+	if err := state.Save(r.RepoRoot, r.State); err != nil {
+		r.warnf("Session not saved: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	relay "github.com/yarlson/relay"
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionMock records how it was invoked and reports a session ID on every run.
+// A non-empty entry in runErrors makes the matching run fail with that event error.
+type sessionMock struct {
+	resumeErr error
+	runErrors []string
+	resumed   []string
+	runs      int
+}
+
+func (m *sessionMock) Name() string { return "mock" }
+
+func (m *sessionMock) Run(_ context.Context, _ relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	m.runs++
+	events <- relay.Event{Kind: relay.EventKindText, SessionID: "sess-new"}
+	if m.runs <= len(m.runErrors) && m.runErrors[m.runs-1] != "" {
+		events <- relay.Event{Kind: "error", Error: m.runErrors[m.runs-1]}
+		return errors.New("exit status 1")
+	}
+	return nil
+}
+
+func (m *sessionMock) Resume(_ context.Context, sessionID string, _ relay.RunParams, events chan<- relay.Event) error {
+	defer close(events)
+	m.resumed = append(m.resumed, sessionID)
+	if m.resumeErr != nil {
+		return m.resumeErr
+	}
+	events <- relay.Event{Kind: relay.EventKindText, SessionID: sessionID}
+	return nil
+}
+
+func collectEvents(t *testing.T, p relay.Provider) ([]relay.Event, error) {
+	t.Helper()
+	events := make(chan relay.Event)
+	var got []relay.Event
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for evt := range events {
+			got = append(got, evt)
+		}
+	}()
+	err := p.Run(context.Background(), relay.RunParams{}, events)
+	<-done
+	return got, err
+}
+
+func TestSessionProvider(t *testing.T) {
+	t.Run("resumes the session once", func(t *testing.T) {
+		mock := &sessionMock{}
+		p := &sessionProvider{Provider: mock, sessionID: "sess-1"}
+
+		events, err := collectEvents(t, p)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sess-1"}, mock.resumed)
+		assert.Equal(t, 0, mock.runs)
+		require.Len(t, events, 1)
+		assert.Equal(t, "sess-1", events[0].SessionID)
+
+		_, err = collectEvents(t, p)
+		require.NoError(t, err)
+		assert.Equal(t, 1, mock.runs)
+	})
+
+	t.Run("falls back to a new session", func(t *testing.T) {
+		mock := &sessionMock{resumeErr: errors.New("session not found")}
+		var fallbackErr error
+		p := &sessionProvider{Provider: mock, sessionID: "sess-1", onFallback: func(err error) { fallbackErr = err }}
+
+		events, err := collectEvents(t, p)
+		require.NoError(t, err)
+		assert.EqualError(t, fallbackErr, "session not found")
+		assert.Equal(t, 1, mock.runs)
+		require.Len(t, events, 1)
+		assert.Equal(t, "sess-new", events[0].SessionID)
+	})
+}
+
+func TestExecuteTask_ResumesSavedSession(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+
+	tasksDir := filepath.Join(repoDir, ".turbine")
+	require.NoError(t, os.MkdirAll(tasksDir, 0755))
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"true"},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(tasksDir, "task.yaml")))
+
+	// The run was interrupted during stroke 2 of T1.
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State: &state.RunState{
+			RunID:            "test-run",
+			ActiveTaskID:     "T1",
+			Rotation:         1,
+			Stroke:           2,
+			BackendName:      "mock",
+			BackendSessionID: "sess-old",
+		},
+		Config: config.Defaults{Retry: config.Retry{Strokes: 3, Rotations: 1}},
+	}
+	head := gitOutput(t, repoDir, "rev-parse", "HEAD")
+	r.State.LastSavepointCommit = head[:len(head)-1]
+
+	mock := &sessionMock{}
+	require.NoError(t, r.ExecuteTask(ctx, mock, "sonnet", ""))
+
+	assert.Equal(t, []string{"sess-old"}, mock.resumed)
+	assert.Equal(t, 0, mock.runs)
+}
+
+func TestExecuteTask_StrokeAfterFallback(t *testing.T) {
+	ctx := context.Background()
+	repoDir := setupTestRepo(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".turbine"), 0755))
+	// The first verification fails, so the task takes a second stroke.
+	marker := filepath.Join(repoDir, ".git", "verified")
+	taskFile := &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            "T1",
+			Title:         "Task 1",
+			Status:        tasks.StatusTodo,
+			Description:   "Description 1",
+			CommitMessage: "feat: task 1",
+			Verify:        []string{"test -f " + marker + " || { touch " + marker + "; false; }"},
+		},
+	}
+	require.NoError(t, taskFile.Save(filepath.Join(repoDir, TaskRelPath)))
+
+	r := &Runner{
+		RepoRoot: repoDir,
+		TaskFile: taskFile,
+		State: &state.RunState{
+			RunID:            "test-run",
+			ActiveTaskID:     "T1",
+			Rotation:         1,
+			Stroke:           1,
+			BackendName:      "mock",
+			BackendSessionID: "sess-old",
+		},
+		Config: config.Defaults{Retry: config.Retry{Strokes: 2, Rotations: 1}},
+	}
+	head := gitOutput(t, repoDir, "rev-parse", "HEAD")
+	r.State.LastSavepointCommit = head[:len(head)-1]
+
+	// No session can be resumed; every stroke falls back to a new one.
+	mock := &sessionMock{resumeErr: errors.New("session not found")}
+	require.NoError(t, r.ExecuteTask(ctx, mock, "sonnet", ""))
+
+	assert.Equal(t, []string{"sess-old", "sess-new"}, mock.resumed)
+	assert.Equal(t, 2, mock.runs)
+}
+
+func TestRunner_RecordSession(t *testing.T) {
+	repoDir := t.TempDir()
+	r := &Runner{RepoRoot: repoDir, State: &state.RunState{RunID: "run-1"}}
+
+	r.recordSession("claude", relay.Event{Kind: relay.EventKindText})
+	_, exists, err := state.Load(repoDir)
+	require.NoError(t, err)
+	assert.False(t, exists, "events without a session are ignored")
+
+	r.recordSession("claude", relay.Event{Kind: relay.EventKindText, SessionID: "sess-1"})
+	saved, exists, err := state.Load(repoDir)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, "sess-1", saved.BackendSessionID)
+	assert.Equal(t, "claude", saved.BackendName)
+
+	other := &sessionMock{}
+	assert.Equal(t, relay.Provider(other), r.strokeProvider(other), "sessions from another backend are not resumed")
+}
@@ -0,0 +1,285 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+// BackupRefPrefix is where squash keeps the pre-rewrite history.
+const BackupRefPrefix = "refs/turbine/backup/"
+
+// SquashPlan describes the commits of a run to rewrite.
+type SquashPlan struct {
+	RunID string
+	// Base is the commit the rewritten history is built on.
+	Base string
+	// Commits are the run's savepoint commits, oldest first.
+	Commits []gitx.Commit
+}
+
+// SquashGroup is a set of consecutive commits rewritten into one commit.
+type SquashGroup struct {
+	Section string
+	Commits []gitx.Commit
+	Tasks   []*tasks.Task
+}
+
+// PlanSquash finds the commits of runID (or of the most recent run when empty) by their
+// Turbine-Run trailer. They must be on top of history and must not have been pushed.
+func PlanSquash(ctx context.Context, repoRoot, runID string) (*SquashPlan, error) {
+	commits, err := gitx.Log(ctx, repoRoot, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+
+	if runID == "" {
+		for _, c := range commits {
+			if id := c.Trailers[TrailerRun]; id != "" {
+				runID = id
+				break
+			}
+		}
+		if runID == "" {
+			return nil, fmt.Errorf("no commits with a %s trailer found", TrailerRun)
+		}
+	}
+
+	if commits[0].Trailers[TrailerRun] != runID {
+		return nil, fmt.Errorf("HEAD %s is not part of run %s; run commits must be on top of history", shortHash(commits[0].Hash), runID)
+	}
+
+	plan := &SquashPlan{RunID: runID}
+	i := 0
+	for ; i < len(commits) && commits[i].Trailers[TrailerRun] == runID; i++ {
+		plan.Commits = append([]gitx.Commit{commits[i]}, plan.Commits...)
+	}
+	for _, c := range commits[i:] {
+		if c.Trailers[TrailerRun] == runID {
+			return nil, fmt.Errorf("run %s commits are interleaved with other commits (e.g. %s)", runID, shortHash(c.Hash))
+		}
+	}
+
+	oldest := plan.Commits[0]
+	if oldest.Parent == "" {
+		return nil, fmt.Errorf("cannot squash onto root commit %s", shortHash(oldest.Hash))
+	}
+	plan.Base = oldest.Parent
+
+	remotes, err := gitx.RemoteRefsContaining(ctx, repoRoot, oldest.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(remotes) > 0 {
+		return nil, fmt.Errorf("run %s has been pushed (%s); refusing to rewrite published history", runID, strings.Join(remotes, ", "))
+	}
+
+	return plan, nil
+}
+
+// Groups splits the plan into commits to create. Without bySection the whole run becomes
+// one group; with it, consecutive tasks of the same PRD section are grouped.
+func (p *SquashPlan) Groups(repoRoot string, bySection bool) []SquashGroup {
+	var groups []SquashGroup
+	for _, c := range p.Commits {
+		task := archivedTask(repoRoot, c.Trailers[TrailerTask])
+		section := ""
+		if task != nil {
+			section = task.Section
+		}
+
+		if len(groups) > 0 && (!bySection || groups[len(groups)-1].Section == section) {
+			last := &groups[len(groups)-1]
+			last.Commits = append(last.Commits, c)
+			last.Tasks = append(last.Tasks, task)
+			continue
+		}
+		groups = append(groups, SquashGroup{Section: section, Commits: []gitx.Commit{c}, Tasks: []*tasks.Task{task}})
+	}
+	if !bySection && len(groups) == 1 {
+		groups[0].Section = ""
+	}
+	return groups
+}
+
+// ApplySquash saves a backup ref and rewrites the plan's commits into the given groups.
+// subject overrides the generated subject of a single-group squash; identity and signing
+// follow the commit config. It returns the backup ref.
+func ApplySquash(ctx context.Context, repoRoot string, plan *SquashPlan, groups []SquashGroup, subject string, cfg config.Commit) (string, error) {
+	opts := commitOptions(cfg, tasks.Task{}, nil)
+	policy := messagePolicy(cfg.Message)
+	subjects := make([]string, len(groups))
+	for i, g := range groups {
+		subjects[i] = subject
+		if len(groups) > 1 || subjects[i] == "" {
+			subjects[i] = squashSubject(plan.RunID, g, policy)
+		}
+		if err := policy.Validate(subjects[i]); err != nil {
+			return "", fmt.Errorf("squashed commit subject: %w", err)
+		}
+	}
+
+	head := plan.Commits[len(plan.Commits)-1].Hash
+	backupRef := BackupRefPrefix + "squash-" + time.Now().UTC().Format("20060102T150405Z")
+	if err := gitx.UpdateRef(ctx, repoRoot, backupRef, head); err != nil {
+		return "", err
+	}
+
+	parent := plan.Base
+	for i, g := range groups {
+		msg := gitx.BuildCommitMessage(subjects[i], squashBody(g), squashTrailers(plan.RunID, g))
+
+		last := g.Commits[len(g.Commits)-1].Hash
+		hash, err := gitx.CommitTree(ctx, repoRoot, last, parent, msg, opts)
+		if err != nil {
+			return backupRef, err
+		}
+		if err := squashNotes(ctx, repoRoot, g, hash); err != nil {
+			return backupRef, err
+		}
+		parent = hash
+	}
+
+	if err := gitx.ResetSoft(ctx, repoRoot, parent); err != nil {
+		return backupRef, err
+	}
+	return backupRef, nil
+}
+
+// squashSubject returns the subject of a group's commit. A group of several tasks
+// is named after its section or the run. Under a Conventional Commits policy it
+// takes the type and scope its subjects share, or else the first one's type, and
+// a long section is shortened to fit the subject limit.
+func squashSubject(runID string, g SquashGroup, policy gitx.MessagePolicy) string {
+	if len(g.Commits) == 1 {
+		return g.Commits[0].Subject
+	}
+	desc := "Turbine run " + runID
+	if g.Section != "" {
+		desc = g.Section
+	}
+	prefix, suffix := "", fmt.Sprintf(" (%d tasks)", len(g.Commits))
+	if policy.Conventional {
+		prefix = squashPrefix(g) + ": "
+	}
+	if policy.MaxSubject > 0 {
+		room := policy.MaxSubject - utf8.RuneCountInString(prefix+suffix)
+		if r := []rune(desc); room > 0 && len(r) > room {
+			desc = string(r[:room-1]) + "…"
+		}
+	}
+	return prefix + desc + suffix
+}
+
+// squashPrefix returns the Conventional Commits type and scope for a group's commit.
+func squashPrefix(g SquashGroup) string {
+	shared, first := "", ""
+	for i, c := range g.Commits {
+		typ, scope, ok := gitx.ParseConventional(c.Subject)
+		if ok && first == "" {
+			first = typ
+		}
+		prefix := typ
+		if scope != "" {
+			prefix += "(" + scope + ")"
+		}
+		if i == 0 {
+			shared = prefix
+		} else if prefix != shared {
+			shared = ""
+		}
+	}
+	if shared != "" {
+		return shared
+	}
+	if first != "" {
+		return first
+	}
+	return "chore"
+}
+
+// squashNotes attaches the notes of a group's commits to the commit replacing
+// them, one YAML document per note. Commits without a note are skipped.
+func squashNotes(ctx context.Context, repoRoot string, g SquashGroup, hash string) error {
+	var notes []string
+	for _, c := range g.Commits {
+		if note, err := gitx.ShowNote(ctx, repoRoot, NotesRef, c.Hash); err == nil && strings.TrimSpace(note) != "" {
+			notes = append(notes, strings.TrimRight(note, "\n")+"\n")
+		}
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+	return gitx.AddNote(ctx, repoRoot, NotesRef, hash, strings.Join(notes, "---\n"))
+}
+
+func squashBody(g SquashGroup) string {
+	var b strings.Builder
+	for i, c := range g.Commits {
+		taskID := c.Trailers[TrailerTask]
+		task := g.Tasks[i]
+		if task == nil {
+			fmt.Fprintf(&b, "- %s (%s)\n", c.Subject, taskID)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", firstLine(task.CommitMessage, c.Subject), taskID)
+		if desc := firstLine(task.Description, ""); desc != "" {
+			fmt.Fprintf(&b, "  %s\n", desc)
+		}
+	}
+	return b.String()
+}
+
+// squashTrailers keeps TrailerTask a single task ID, the group's last, and lists
+// every task, including those of commits squashed before, in TrailerTasks.
+func squashTrailers(runID string, g SquashGroup) []string {
+	ids := make([]string, 0, len(g.Commits))
+	for _, c := range g.Commits {
+		if squashed := c.Trailers[TrailerTasks]; squashed != "" {
+			ids = append(ids, strings.Split(squashed, ", ")...)
+		} else {
+			ids = append(ids, c.Trailers[TrailerTask])
+		}
+	}
+	trailers := []string{fmt.Sprintf("%s: %s", TrailerTask, ids[len(ids)-1])}
+	if len(ids) > 1 {
+		trailers = append(trailers, fmt.Sprintf("%s: %s", TrailerTasks, strings.Join(ids, ", ")))
+	}
+	return append(trailers, fmt.Sprintf("%s: %s", TrailerRun, runID))
+}
+
+// archivedTask returns the most recently archived task file for taskID, or nil if none.
+func archivedTask(repoRoot, taskID string) *tasks.Task {
+	if taskID == "" {
+		return nil
+	}
+	matches, err := filepath.Glob(filepath.Join(repoRoot, ArchiveRelDir, "*-"+taskID+".yaml"))
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+	sort.Strings(matches)
+	tf, err := tasks.LoadTaskFile(matches[len(matches)-1])
+	if err != nil {
+		return nil
+	}
+	return &tf.Task
+}
+
+func firstLine(s, fallback string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return fallback
+}
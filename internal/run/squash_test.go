@@ -0,0 +1,184 @@
+package run
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/tasks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func archiveTestTask(t *testing.T, repoDir, id, section string) {
+	t.Helper()
+	_, err := ArchiveTaskFile(repoDir, &tasks.TaskFile{
+		Version: 1,
+		Task: tasks.Task{
+			ID:            id,
+			Title:         "Task " + id,
+			Status:        tasks.StatusDone,
+			Description:   "Implement " + id + ".\nMore details.",
+			CommitMessage: "feat: " + id,
+			Section:       section,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestPlanSquash(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("latest run on top", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001", "Turbine-Run: run-1")
+		commitFile(t, repoDir, "b.txt", "feat: b", "Turbine: T-002", "Turbine-Run: run-2")
+		commitFile(t, repoDir, "c.txt", "feat: c", "Turbine: T-003", "Turbine-Run: run-2")
+
+		plan, err := PlanSquash(ctx, repoDir, "")
+		require.NoError(t, err)
+		assert.Equal(t, "run-2", plan.RunID)
+		require.Len(t, plan.Commits, 2)
+		assert.Equal(t, "feat: b", plan.Commits[0].Subject)
+		assert.Equal(t, "feat: c", plan.Commits[1].Subject)
+	})
+
+	t.Run("run not on top", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001", "Turbine-Run: run-1")
+		commitFile(t, repoDir, "b.txt", "chore: manual")
+
+		_, err := PlanSquash(ctx, repoDir, "run-1")
+		assert.ErrorContains(t, err, "must be on top of history")
+	})
+
+	t.Run("interleaved run commits", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		stray := commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001", "Turbine-Run: run-1")
+		commitFile(t, repoDir, "b.txt", "chore: manual")
+		commitFile(t, repoDir, "c.txt", "feat: c", "Turbine: T-002", "Turbine-Run: run-1")
+
+		_, err := PlanSquash(ctx, repoDir, "run-1")
+		assert.EqualError(t, err, "run run-1 commits are interleaved with other commits (e.g. "+shortHash(stray)+")")
+	})
+
+	t.Run("pushed history", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		hash := commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001", "Turbine-Run: run-1")
+		require.NoError(t, gitx.UpdateRef(ctx, repoDir, "refs/remotes/origin/main", hash))
+
+		_, err := PlanSquash(ctx, repoDir, "run-1")
+		assert.ErrorContains(t, err, "has been pushed")
+	})
+}
+
+func TestApplySquash(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("single commit", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		base, err := gitx.CurrentHash(ctx, repoDir)
+		require.NoError(t, err)
+		archiveTestTask(t, repoDir, "T-001", "Parser")
+		commitFile(t, repoDir, "a.txt", "feat: T-001", "Turbine: T-001", "Turbine-Run: run-1")
+		archiveTestTask(t, repoDir, "T-002", "CLI")
+		head := commitFile(t, repoDir, "b.txt", "feat: T-002", "Turbine: T-002", "Turbine-Run: run-1")
+
+		plan, err := PlanSquash(ctx, repoDir, "run-1")
+		require.NoError(t, err)
+		groups := plan.Groups(repoDir, false)
+		require.Len(t, groups, 1)
+
+		backupRef, err := ApplySquash(ctx, repoDir, plan, groups, "feat: parser and CLI", config.Commit{})
+		require.NoError(t, err)
+
+		commits, err := gitx.Log(ctx, repoDir, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "feat: parser and CLI", commits[0].Subject)
+		assert.Equal(t, base, commits[0].Parent)
+		assert.Equal(t, "T-002", commits[0].Trailers[TrailerTask], "the task trailer stays a single ID")
+		assert.Equal(t, "T-001, T-002", commits[0].Trailers[TrailerTasks])
+		assert.Equal(t, "run-1", commits[0].Trailers[TrailerRun])
+
+		msg := gitOutput(t, repoDir, "log", "-1", "--pretty=%B")
+		assert.Contains(t, msg, "- feat: T-001 (T-001)\n  Implement T-001.")
+
+		backup, err := gitx.LogRange(ctx, repoDir, backupRef, 1)
+		require.NoError(t, err)
+		assert.Equal(t, head, backup[0].Hash)
+	})
+
+	t.Run("grouped by section", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		archiveTestTask(t, repoDir, "T-001", "Parser")
+		commitFile(t, repoDir, "a.txt", "feat: T-001", "Turbine: T-001", "Turbine-Run: run-1")
+		archiveTestTask(t, repoDir, "T-002", "Parser")
+		commitFile(t, repoDir, "b.txt", "feat: T-002", "Turbine: T-002", "Turbine-Run: run-1")
+		archiveTestTask(t, repoDir, "T-003", "CLI")
+		commitFile(t, repoDir, "c.txt", "feat: T-003", "Turbine: T-003", "Turbine-Run: run-1")
+
+		plan, err := PlanSquash(ctx, repoDir, "run-1")
+		require.NoError(t, err)
+		groups := plan.Groups(repoDir, true)
+		require.Len(t, groups, 2)
+
+		_, err = ApplySquash(ctx, repoDir, plan, groups, "", config.Commit{})
+		require.NoError(t, err)
+
+		commits, err := gitx.Log(ctx, repoDir, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "feat: T-003", commits[0].Subject)
+		assert.Equal(t, "Parser (2 tasks)", commits[1].Subject)
+		assert.Equal(t, "T-002", commits[1].Trailers[TrailerTask])
+		assert.Equal(t, "T-001, T-002", commits[1].Trailers[TrailerTasks])
+		assert.Empty(t, commits[0].Trailers[TrailerTasks], "a single task is not listed")
+		assert.Equal(t, "initial commit", commits[2].Subject)
+		assert.FileExists(t, filepath.Join(repoDir, "c.txt"))
+
+		// Squashing again keeps every task and finds the archived ones.
+		plan, err = PlanSquash(ctx, repoDir, "run-1")
+		require.NoError(t, err)
+		groups = plan.Groups(repoDir, false)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "Parser", groups[0].Tasks[0].Section)
+		_, err = ApplySquash(ctx, repoDir, plan, groups, "feat: all", config.Commit{})
+		require.NoError(t, err)
+		commits, err = gitx.Log(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "T-003", commits[0].Trailers[TrailerTask])
+		assert.Equal(t, "T-001, T-002, T-003", commits[0].Trailers[TrailerTasks])
+	})
+	t.Run("message policy and notes", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		archiveTestTask(t, repoDir, "T-001", "A parser for the whole configuration language")
+		first := commitFile(t, repoDir, "a.txt", "feat(parser): T-001", "Turbine: T-001", "Turbine-Run: run-1")
+		require.NoError(t, gitx.AddNote(ctx, repoDir, NotesRef, first, "run: run-1\ntask: T-001\n"))
+		archiveTestTask(t, repoDir, "T-002", "A parser for the whole configuration language")
+		second := commitFile(t, repoDir, "b.txt", "feat(parser): T-002", "Turbine: T-002", "Turbine-Run: run-1")
+		require.NoError(t, gitx.AddNote(ctx, repoDir, NotesRef, second, "run: run-1\ntask: T-002\n"))
+
+		plan, err := PlanSquash(ctx, repoDir, "run-1")
+		require.NoError(t, err)
+		groups := plan.Groups(repoDir, true)
+		cfg := config.Commit{Message: config.CommitMessage{Conventional: true, MaxSubject: 40}}
+
+		_, err = ApplySquash(ctx, repoDir, plan, groups, "", cfg)
+		require.NoError(t, err)
+		commits, err := gitx.Log(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "feat(parser): A parser for th… (2 tasks)", commits[0].Subject)
+		assert.Equal(t, "run: run-1\ntask: T-001\n---\nrun: run-1\ntask: T-002\n",
+			gitOutput(t, repoDir, "notes", "--ref", NotesRef, "show", "HEAD"))
+
+		plan, err = PlanSquash(ctx, repoDir, "run-1")
+		require.NoError(t, err)
+		_, err = ApplySquash(ctx, repoDir, plan, plan.Groups(repoDir, false), "Squash it all", cfg)
+		assert.ErrorContains(t, err, "squashed commit subject: commit subject \"Squash it all\" does not follow Conventional Commits")
+		unchanged, err := gitx.Log(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.Equal(t, commits[0].Hash, unchanged[0].Hash)
+	})
+}
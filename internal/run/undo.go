@@ -0,0 +1,139 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/state"
+	"github.com/yarlson/turbine/internal/tasks"
+)
+
+// UndoPlan describes the Turbine savepoint commits to roll back.
+type UndoPlan struct {
+	// Savepoints are the Turbine commits to undo, newest first.
+	Savepoints []gitx.Commit
+	// Interleaved are unrelated commits found between the savepoints.
+	Interleaved []gitx.Commit
+}
+
+// OnTop reports whether the savepoints are the most recent commits, so a reset is safe.
+func (p *UndoPlan) OnTop() bool {
+	return len(p.Interleaved) == 0
+}
+
+// PlanUndo locates the last n Turbine savepoint commits on the first-parent history of HEAD.
+// Savepoints already reverted by turbine undo, and the revert commits themselves, are skipped.
+func PlanUndo(ctx context.Context, repoRoot string, n int) (*UndoPlan, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("number of tasks to undo must be at least 1")
+	}
+
+	commits, err := gitx.Log(ctx, repoRoot, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &UndoPlan{}
+	var pending []gitx.Commit
+	reverted := map[string]bool{}
+	for _, c := range commits {
+		if hash := c.Trailers[TrailerReverts]; hash != "" {
+			reverted[hash] = true
+			continue
+		}
+		if reverted[c.Hash] {
+			continue
+		}
+		if c.Trailers[TrailerTask] == "" {
+			pending = append(pending, c)
+			continue
+		}
+		plan.Interleaved = append(plan.Interleaved, pending...)
+		pending = nil
+		plan.Savepoints = append(plan.Savepoints, c)
+		if len(plan.Savepoints) == n {
+			break
+		}
+	}
+
+	if len(plan.Savepoints) < n {
+		return nil, fmt.Errorf("found %d Turbine commits, cannot undo %d", len(plan.Savepoints), n)
+	}
+
+	return plan, nil
+}
+
+// ApplyUndo rolls back the planned savepoints, either by resetting to the parent of the
+// oldest savepoint or by creating revert commits with the identity and signing of cfg.
+// Reverted tasks are recorded in the progress log so the planner plans them again.
+func ApplyUndo(ctx context.Context, repoRoot string, plan *UndoPlan, revert bool, cfg config.Commit) error {
+	if !revert && !plan.OnTop() {
+		return fmt.Errorf("unrelated commits are interleaved with Turbine commits; revert instead of reset")
+	}
+
+	// The progress log is appended to after each commit, so keep the current narrative
+	// rather than the version stored in history.
+	progressPath := filepath.Join(repoRoot, ProgressRelPath)
+	progress, err := os.ReadFile(progressPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read progress file: %w", err)
+	}
+
+	if revert {
+		for _, c := range plan.Savepoints {
+			opts := commitOptions(cfg, tasks.Task{}, []string{fmt.Sprintf("%s: %s", TrailerReverts, c.Hash)})
+			if _, err := gitx.Revert(ctx, repoRoot, c, opts, ".turbine"); err != nil {
+				return err
+			}
+		}
+	} else {
+		oldest := plan.Savepoints[len(plan.Savepoints)-1]
+		if oldest.Parent == "" {
+			return fmt.Errorf("cannot reset past root commit %s; revert instead", oldest.Hash)
+		}
+		if err := gitx.ResetHard(ctx, repoRoot, oldest.Parent); err != nil {
+			return err
+		}
+	}
+
+	if progress != nil {
+		if err := os.WriteFile(progressPath, progress, 0644); err != nil {
+			return fmt.Errorf("restore progress file: %w", err)
+		}
+	}
+	for _, c := range plan.Savepoints {
+		entry := fmt.Sprintf("- %s %s %s - reverted (commit %s)", timeNowUTC(), c.Trailers[TrailerTask], c.Subject, shortHash(c.Hash))
+		if err := AppendProgress(repoRoot, entry); err != nil {
+			return err
+		}
+	}
+
+	// Keep an interrupted run from resetting back onto undone commits.
+	runState, exists, err := state.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+	if exists {
+		hash, err := gitx.CurrentHash(ctx, repoRoot)
+		if err != nil {
+			return err
+		}
+		runState.LastSavepointCommit = hash
+		if err := state.Save(repoRoot, runState); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
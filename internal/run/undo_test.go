@@ -0,0 +1,151 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yarlson/turbine/internal/config"
+	"github.com/yarlson/turbine/internal/gitx"
+	"github.com/yarlson/turbine/internal/state"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitFile(t *testing.T, repoDir, name, subject string, trailers ...string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, name), []byte(name), 0644))
+	hash, err := gitx.CommitSavePoint(context.Background(), repoDir, subject, trailers...)
+	require.NoError(t, err)
+	return hash
+}
+
+func TestPlanUndo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("savepoints on top", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+		commitFile(t, repoDir, "b.txt", "feat: b", "Turbine: T-002")
+
+		plan, err := PlanUndo(ctx, repoDir, 2)
+		require.NoError(t, err)
+		require.Len(t, plan.Savepoints, 2)
+		assert.Equal(t, "T-002", plan.Savepoints[0].Trailers[TrailerTask])
+		assert.Equal(t, "T-001", plan.Savepoints[1].Trailers[TrailerTask])
+		assert.True(t, plan.OnTop())
+	})
+
+	t.Run("interleaved commits", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+		commitFile(t, repoDir, "manual.txt", "chore: manual")
+		commitFile(t, repoDir, "b.txt", "feat: b", "Turbine: T-002")
+
+		plan, err := PlanUndo(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.True(t, plan.OnTop())
+
+		plan, err = PlanUndo(ctx, repoDir, 2)
+		require.NoError(t, err)
+		assert.False(t, plan.OnTop())
+		require.Len(t, plan.Interleaved, 1)
+		assert.Equal(t, "chore: manual", plan.Interleaved[0].Subject)
+	})
+
+	t.Run("unrelated commits on top", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+		commitFile(t, repoDir, "manual.txt", "chore: manual")
+
+		plan, err := PlanUndo(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.False(t, plan.OnTop())
+	})
+
+	t.Run("not enough savepoints", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+
+		_, err := PlanUndo(ctx, repoDir, 2)
+		assert.ErrorContains(t, err, "found 1 Turbine commits, cannot undo 2")
+	})
+}
+
+func TestApplyUndo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reset when on top", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		base, err := gitx.CurrentHash(ctx, repoDir)
+		require.NoError(t, err)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+		require.NoError(t, AppendProgress(repoDir, "- T-001 done"))
+
+		require.NoError(t, state.Save(repoDir, &state.RunState{RunID: "r", LastSavepointCommit: "stale"}))
+
+		plan, err := PlanUndo(ctx, repoDir, 1)
+		require.NoError(t, err)
+		require.NoError(t, ApplyUndo(ctx, repoDir, plan, false, config.Commit{}))
+
+		head, err := gitx.CurrentHash(ctx, repoDir)
+		require.NoError(t, err)
+		assert.Equal(t, base, head)
+		assert.NoFileExists(t, filepath.Join(repoDir, "a.txt"))
+
+		progress, err := os.ReadFile(filepath.Join(repoDir, ProgressRelPath))
+		require.NoError(t, err)
+		assert.Contains(t, string(progress), "- T-001 done")
+		assert.Contains(t, string(progress), "T-001 feat: a - reverted")
+
+		runState, _, err := state.Load(repoDir)
+		require.NoError(t, err)
+		assert.Equal(t, base, runState.LastSavepointCommit)
+	})
+
+	t.Run("revert keeps interleaved commits", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+		commitFile(t, repoDir, "manual.txt", "chore: manual")
+
+		plan, err := PlanUndo(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.ErrorContains(t, ApplyUndo(ctx, repoDir, plan, false, config.Commit{}), "interleaved")
+
+		require.NoError(t, ApplyUndo(ctx, repoDir, plan, true, config.Commit{}))
+		assert.NoFileExists(t, filepath.Join(repoDir, "a.txt"))
+		assert.FileExists(t, filepath.Join(repoDir, "manual.txt"))
+
+		commits, err := gitx.Log(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.Equal(t, `Revert "feat: a"`, commits[0].Subject)
+		assert.Equal(t, plan.Savepoints[0].Hash, commits[0].Trailers[TrailerReverts])
+	})
+
+	t.Run("undo twice skips reverted savepoints", func(t *testing.T) {
+		repoDir := setupTestRepo(t)
+		commitFile(t, repoDir, "a.txt", "feat: a", "Turbine: T-001")
+		second := commitFile(t, repoDir, "b.txt", "feat: b", "Turbine: T-002")
+		commitFile(t, repoDir, "manual.txt", "chore: manual")
+
+		plan, err := PlanUndo(ctx, repoDir, 1)
+		require.NoError(t, err)
+		assert.Equal(t, second, plan.Savepoints[0].Hash)
+		require.NoError(t, ApplyUndo(ctx, repoDir, plan, true, config.Commit{}))
+
+		plan, err = PlanUndo(ctx, repoDir, 1)
+		require.NoError(t, err)
+		require.Len(t, plan.Savepoints, 1)
+		assert.Equal(t, "T-001", plan.Savepoints[0].Trailers[TrailerTask])
+		require.Len(t, plan.Interleaved, 1, "the revert commit is not an unrelated commit")
+		assert.Equal(t, "chore: manual", plan.Interleaved[0].Subject)
+		require.NoError(t, ApplyUndo(ctx, repoDir, plan, true, config.Commit{}))
+		assert.NoFileExists(t, filepath.Join(repoDir, "a.txt"))
+		assert.NoFileExists(t, filepath.Join(repoDir, "b.txt"))
+
+		_, err = PlanUndo(ctx, repoDir, 1)
+		assert.ErrorContains(t, err, "found 0 Turbine commits")
+	})
+}
@@ -0,0 +1,106 @@
+// Package schema versions Turbine's persistent files and upgrades old ones on load.
+package schema
+
+import (
+	"fmt"
+	"math"
+)
+
+// Migration upgrades a decoded document by one version. It may modify doc in place
+// or return a replacement.
+type Migration func(doc map[string]any) (map[string]any, error)
+
+// NewerVersionError reports a file written by a newer Turbine than this one.
+type NewerVersionError struct {
+	File      string
+	Version   int
+	Supported int
+}
+
+func (e *NewerVersionError) Error() string {
+	return fmt.Sprintf("%s has schema version %d, but this turbine supports up to version %d; upgrade turbine to continue",
+		e.File, e.Version, e.Supported)
+}
+
+// Registry holds the migrations for one kind of file.
+type Registry struct {
+	file        string
+	key         string
+	current     int
+	unversioned int
+	migrations  map[int]Migration
+}
+
+// New returns a registry for file whose version is stored under key. Documents
+// without the key are treated as version unversioned; zero leaves them untouched.
+func New(file, key string, current, unversioned int) *Registry {
+	return &Registry{
+		file:        file,
+		key:         key,
+		current:     current,
+		unversioned: unversioned,
+		migrations:  map[int]Migration{},
+	}
+}
+
+// Register adds the migration from version from to from+1.
+func (r *Registry) Register(from int, m Migration) *Registry {
+	r.migrations[from] = m
+	return r
+}
+
+// Current returns the version this build writes.
+func (r *Registry) Current() int {
+	return r.current
+}
+
+// Version returns the schema version of doc.
+func (r *Registry) Version(doc map[string]any) (int, error) {
+	raw, ok := doc[r.key]
+	if !ok || raw == nil {
+		return r.unversioned, nil
+	}
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%s: %s must be an integer, got %v", r.file, r.key, v)
+		}
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("%s: %s must be an integer, got %v", r.file, r.key, raw)
+	}
+}
+
+// Migrate upgrades doc to the current version, reporting whether it changed.
+// A document from a newer version fails with *NewerVersionError.
+func (r *Registry) Migrate(doc map[string]any) (map[string]any, bool, error) {
+	version, err := r.Version(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > r.current {
+		return nil, false, &NewerVersionError{File: r.file, Version: version, Supported: r.current}
+	}
+	if version == 0 || version == r.current {
+		return doc, false, nil
+	}
+
+	for v := version; v < r.current; v++ {
+		m, ok := r.migrations[v]
+		if !ok {
+			return nil, false, fmt.Errorf("%s: no migration from schema version %d", r.file, v)
+		}
+		doc, err = m(doc)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: migrate schema version %d to %d: %w", r.file, v, v+1, err)
+		}
+	}
+	doc[r.key] = r.current
+	return doc, true, nil
+}
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryMigrate(t *testing.T) {
+	newRegistry := func() *Registry {
+		return New("data.json", "schema_version", 3, 1).
+			Register(1, func(doc map[string]any) (map[string]any, error) {
+				return map[string]any{"items": doc}, nil
+			}).
+			Register(2, func(doc map[string]any) (map[string]any, error) {
+				doc["renamed"] = doc["items"]
+				delete(doc, "items")
+				return doc, nil
+			})
+	}
+
+	t.Run("unversioned document runs every migration", func(t *testing.T) {
+		doc, migrated, err := newRegistry().Migrate(map[string]any{"a": 1})
+		require.NoError(t, err)
+		assert.True(t, migrated)
+		assert.Equal(t, map[string]any{"renamed": map[string]any{"a": 1}, "schema_version": 3}, doc)
+	})
+
+	t.Run("partial upgrade", func(t *testing.T) {
+		doc, migrated, err := newRegistry().Migrate(map[string]any{"schema_version": float64(2), "items": "x"})
+		require.NoError(t, err)
+		assert.True(t, migrated)
+		assert.Equal(t, "x", doc["renamed"])
+	})
+
+	t.Run("current document is unchanged", func(t *testing.T) {
+		in := map[string]any{"schema_version": 3, "renamed": "x"}
+		doc, migrated, err := newRegistry().Migrate(in)
+		require.NoError(t, err)
+		assert.False(t, migrated)
+		assert.Equal(t, in, doc)
+	})
+
+	t.Run("newer document", func(t *testing.T) {
+		_, _, err := newRegistry().Migrate(map[string]any{"schema_version": 4})
+		var newer *NewerVersionError
+		require.True(t, errors.As(err, &newer))
+		assert.Equal(t, 4, newer.Version)
+		assert.Equal(t, 3, newer.Supported)
+		assert.Contains(t, err.Error(), "upgrade turbine")
+	})
+
+	t.Run("missing migration", func(t *testing.T) {
+		_, _, err := New("data.json", "schema_version", 2, 1).Migrate(map[string]any{})
+		assert.ErrorContains(t, err, "no migration from schema version 1")
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, _, err := newRegistry().Migrate(map[string]any{"schema_version": "two"})
+		assert.ErrorContains(t, err, "must be an integer")
+	})
+
+	t.Run("unversioned zero leaves document alone", func(t *testing.T) {
+		doc, migrated, err := New("task.yaml", "version", 1, 0).Migrate(map[string]any{"task": "x"})
+		require.NoError(t, err)
+		assert.False(t, migrated)
+		assert.Equal(t, map[string]any{"task": "x"}, doc)
+	})
+}
@@ -0,0 +1,161 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// LockRelPath is the run lock file, relative to the repository root.
+	LockRelPath = ".turbine/state/run.lock"
+)
+
+// LockInfo identifies the process holding the run lock.
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// LockedError is returned when another live Turbine process holds the run lock.
+type LockedError struct {
+	Path string
+	Info LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("another turbine process is running (pid %d on %s, started %s); if it is gone, remove %s",
+		e.Info.PID, e.Info.Hostname, e.Info.StartedAt.Local().Format(time.RFC3339), e.Path)
+}
+
+// Lock is an advisory lock on a repository's Turbine state.
+type Lock struct {
+	path string
+}
+
+// AcquireLock takes the run lock for repoRoot. A lock left behind by a process that no
+// longer exists on this host is treated as stale and replaced; any other lock, including
+// one that cannot be read, is left alone.
+func AcquireLock(repoRoot string) (*Lock, error) {
+	path := filepath.Join(repoRoot, LockRelPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create state directory: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	info := LockInfo{PID: os.Getpid(), Hostname: hostname, StartedAt: time.Now().UTC()}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal lock: %w", err)
+	}
+
+	// The lock is written to a temporary file and linked into place, so it never
+	// exists without its content.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "run.lock.*")
+	if err != nil {
+		return nil, fmt.Errorf("create lock file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, writeErr := tmp.Write(data)
+	if err := errors.Join(writeErr, tmp.Close()); err != nil {
+		return nil, fmt.Errorf("write lock file: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		err := os.Link(tmp.Name(), path)
+		if err == nil {
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+
+		held, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Released meanwhile.
+		} else if err != nil {
+			return nil, fmt.Errorf("read lock file: %w", err)
+		}
+		holder, err := parseLock(held)
+		if err != nil {
+			return nil, fmt.Errorf("unreadable lock file %s: %w; if no turbine process is running, remove it", path, err)
+		}
+		if !holder.stale(hostname) {
+			return nil, &LockedError{Path: path, Info: holder}
+		}
+		if err := removeStaleLock(path, tmp.Name()+".stale", held); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("acquire lock %s: lock changed concurrently", path)
+}
+
+// removeStaleLock removes the lock at path if it still has the content judged
+// stale. The lock is first moved to aside, a path unique to the caller, so a lock
+// that another process took in the meantime is put back rather than removed.
+func removeStaleLock(path, aside string, stale []byte) error {
+	if err := os.Rename(path, aside); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("remove stale lock file: %w", err)
+	}
+	defer func() { _ = os.Remove(aside) }()
+	moved, err := os.ReadFile(aside)
+	if err != nil {
+		return fmt.Errorf("remove stale lock file: %w", err)
+	}
+	if !bytes.Equal(moved, stale) {
+		if err := os.Link(aside, path); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("restore lock file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Release removes the lock file. It is safe to call on a nil lock or more than once.
+func (l *Lock) Release() error {
+	if l == nil || l.path == "" {
+		return nil
+	}
+	path := l.path
+	l.path = ""
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove lock file: %w", err)
+	}
+	return nil
+}
+
+func readLock(path string) (LockInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LockInfo{}, err
+	}
+	return parseLock(data)
+}
+
+func parseLock(data []byte) (LockInfo, error) {
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, err
+	}
+	if info.PID <= 0 {
+		return info, fmt.Errorf("invalid pid %d", info.PID)
+	}
+	return info, nil
+}
+
+// stale reports whether the lock holder is known to be gone. Locks from other hosts
+// cannot be checked and are never considered stale.
+func (i LockInfo) stale(hostname string) bool {
+	if i.Hostname != hostname {
+		return false
+	}
+	return !processAlive(i.PID)
+}
@@ -0,0 +1,121 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLockFile(t *testing.T, repoRoot string, info LockInfo) {
+	t.Helper()
+	path := filepath.Join(repoRoot, LockRelPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	data, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestAcquireLock(t *testing.T) {
+	hostname, _ := os.Hostname()
+
+	t.Run("acquire and release", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		lock, err := AcquireLock(repoRoot)
+		require.NoError(t, err)
+
+		info, err := readLock(filepath.Join(repoRoot, LockRelPath))
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), info.PID)
+		assert.Equal(t, hostname, info.Hostname)
+
+		require.NoError(t, lock.Release())
+		assert.NoFileExists(t, filepath.Join(repoRoot, LockRelPath))
+		require.NoError(t, lock.Release())
+
+		lock, err = AcquireLock(repoRoot)
+		require.NoError(t, err)
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("held by live process", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		lock, err := AcquireLock(repoRoot)
+		require.NoError(t, err)
+		defer func() { _ = lock.Release() }()
+
+		_, err = AcquireLock(repoRoot)
+		var lockedErr *LockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.Equal(t, os.Getpid(), lockedErr.Info.PID)
+		assert.Contains(t, err.Error(), "another turbine process is running")
+	})
+
+	t.Run("stale lock from dead process", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		// PIDs are capped well below this value on supported platforms.
+		writeLockFile(t, repoRoot, LockInfo{PID: 1 << 30, Hostname: hostname, StartedAt: time.Now()})
+
+		lock, err := AcquireLock(repoRoot)
+		require.NoError(t, err)
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("lock from another host", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		writeLockFile(t, repoRoot, LockInfo{PID: 1 << 30, Hostname: hostname + "-other", StartedAt: time.Now()})
+
+		_, err := AcquireLock(repoRoot)
+		assert.ErrorContains(t, err, "another turbine process is running")
+	})
+
+	t.Run("corrupt lock file", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		path := filepath.Join(repoRoot, LockRelPath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+		_, err := AcquireLock(repoRoot)
+		assert.ErrorContains(t, err, "unreadable lock file")
+		assert.FileExists(t, path, "a lock that cannot be read may be held")
+	})
+
+	t.Run("concurrent acquire", func(t *testing.T) {
+		for _, stale := range []bool{false, true} {
+			repoRoot := t.TempDir()
+			if stale {
+				writeLockFile(t, repoRoot, LockInfo{PID: 1 << 30, Hostname: hostname, StartedAt: time.Now()})
+			}
+
+			const n = 8
+			var (
+				wg       sync.WaitGroup
+				acquired atomic.Int32
+				start    = make(chan struct{})
+			)
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					if _, err := AcquireLock(repoRoot); err == nil {
+						acquired.Add(1)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+			assert.Equal(t, int32(1), acquired.Load(), "stale=%v", stale)
+
+			info, err := readLock(filepath.Join(repoRoot, LockRelPath))
+			require.NoError(t, err)
+			assert.Equal(t, os.Getpid(), info.PID)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package state
+
+import "os"
+
+// processAlive reports whether a process with the given PID exists.
+// On Windows, FindProcess fails when the process does not exist.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
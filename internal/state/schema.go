@@ -0,0 +1,40 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/yarlson/turbine/internal/atomicfile"
+	"github.com/yarlson/turbine/internal/schema"
+)
+
+// SchemaVersion is the run.json schema version written by this build.
+// Files written before versioning was introduced are version 1.
+const SchemaVersion = 1
+
+// runStateSchema upgrades run.json files written by older builds. Register a
+// migration here whenever RunState changes incompatibly and bump SchemaVersion.
+var runStateSchema = schema.New("run.json", "schema_version", SchemaVersion, 1)
+
+// decodeRunState migrates and decodes a run.json document.
+func decodeRunState(data []byte, state *RunState) error {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	doc, migrated, err := runStateSchema.Migrate(doc)
+	if err != nil {
+		var newer *schema.NewerVersionError
+		if errors.As(err, &newer) {
+			return atomicfile.Unrecoverable(err)
+		}
+		return err
+	}
+	if migrated {
+		if data, err = json.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	*state = RunState{}
+	return json.Unmarshal(data, state)
+}
@@ -5,58 +5,66 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"os"
 	"path/filepath"
+
+	"github.com/yarlson/turbine/internal/atomicfile"
+	"github.com/yarlson/turbine/internal/schema"
 )
 
 const (
-	stateRelPath = ".turbine/state/run.json"
+	stateRelPath       = ".turbine/state/run.json"
+	stateBackupRelPath = ".turbine/state/run.json.bak"
 )
 
-// Load reads the run state from .turbine/state/run.json.
+// Load reads the run state from .turbine/state/run.json, falling back to the
+// backup copy written by the previous Save if the file is corrupt.
 func Load(repoRoot string) (*RunState, bool, error) {
 	path := filepath.Join(repoRoot, stateRelPath)
-	data, err := os.ReadFile(path)
+	var state RunState
+	read := false
+	err := atomicfile.ReadFile(path, filepath.Join(repoRoot, stateBackupRelPath), func(data []byte) error {
+		read = true
+		return decodeRunState(data, &state)
+	})
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return nil, false, nil
 		}
+		var newer *schema.NewerVersionError
+		if errors.As(err, &newer) {
+			return nil, true, err
+		}
+		if read {
+			return nil, true, fmt.Errorf("unmarshal state: %w", err)
+		}
 		return nil, false, fmt.Errorf("read state file: %w", err)
 	}
 
-	var state RunState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, true, fmt.Errorf("unmarshal state: %w", err)
-	}
-
 	return &state, true, nil
 }
 
-// Save writes the run state to .turbine/state/run.json.
+// Save atomically writes the run state to .turbine/state/run.json, keeping the
+// previous version as a backup.
 func Save(repoRoot string, state *RunState) error {
 	path := filepath.Join(repoRoot, stateRelPath)
-	dir := filepath.Dir(path)
-
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("create state directory: %w", err)
-	}
 
+	state.SchemaVersion = SchemaVersion
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	check := func(data []byte) error { return decodeRunState(data, &RunState{}) }
+	if err := atomicfile.WriteFile(path, data, 0644, filepath.Join(repoRoot, stateBackupRelPath), check); err != nil {
 		return fmt.Errorf("write state file: %w", err)
 	}
 
 	return nil
 }
 
-// Clear removes the run state file.
+// Clear removes the run state file and its backup.
 func Clear(repoRoot string) error {
-	path := filepath.Join(repoRoot, stateRelPath)
-	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+	if err := atomicfile.Remove(filepath.Join(repoRoot, stateRelPath), filepath.Join(repoRoot, stateBackupRelPath)); err != nil {
 		return fmt.Errorf("remove state file: %w", err)
 	}
 	return nil
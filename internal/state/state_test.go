@@ -66,3 +66,71 @@ func TestClearNonExistent(t *testing.T) {
 	err = Clear(tmpDir)
 	assert.NoError(t, err)
 }
+
+func TestLoadRecoversFromBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, Save(tmpDir, &RunState{RunID: "run-1", Stroke: 1}))
+	require.NoError(t, Save(tmpDir, &RunState{RunID: "run-1", Stroke: 2}))
+
+	// Simulate a write that was cut short.
+	statePath := filepath.Join(tmpDir, stateRelPath)
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"run_id": "run-1", "str`), 0644))
+
+	state, exists, err := Load(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "run-1", state.RunID)
+	assert.Equal(t, 1, state.Stroke)
+
+	require.NoError(t, Clear(tmpDir))
+	assert.NoFileExists(t, filepath.Join(tmpDir, stateBackupRelPath))
+}
+
+func TestLoadCorruptWithoutBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, stateRelPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(statePath), 0755))
+	require.NoError(t, os.WriteFile(statePath, []byte(`{`), 0644))
+
+	_, exists, err := Load(tmpDir)
+	assert.True(t, exists)
+	assert.ErrorContains(t, err, "unmarshal state")
+}
+
+func TestLoadSchemaVersion(t *testing.T) {
+	writeState := func(t *testing.T, dir, content string) {
+		t.Helper()
+		path := filepath.Join(dir, stateRelPath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	t.Run("unversioned file from an older build", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeState(t, tmpDir, `{"run_id": "old-run", "stroke": 3}`)
+
+		state, exists, err := Load(tmpDir)
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "old-run", state.RunID)
+		assert.Equal(t, 3, state.Stroke)
+
+		require.NoError(t, Save(tmpDir, state))
+		data, err := os.ReadFile(filepath.Join(tmpDir, stateRelPath))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"schema_version": 1`)
+	})
+
+	t.Run("file from a newer build", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, Save(tmpDir, &RunState{RunID: "run-1"}))
+		writeState(t, tmpDir, `{"schema_version": 99, "run_id": "future"}`)
+
+		_, exists, err := Load(tmpDir)
+		assert.True(t, exists)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "schema version 99")
+		assert.Contains(t, err.Error(), "upgrade turbine")
+	})
+}
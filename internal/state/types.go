@@ -2,6 +2,7 @@ package state
 
 // RunState represents the persistent state of a run to support resuming.
 type RunState struct {
+	SchemaVersion       int    `json:"schema_version"`
 	RunID               string `json:"run_id"`
 	ActiveTaskID        string `json:"active_task_id"`
 	Rotation            int    `json:"rotation"`
@@ -10,4 +11,15 @@ type RunState struct {
 	BackendSessionID    string `json:"backend_session_id"`
 	LastSavepointCommit string `json:"last_savepoint_commit"`
 	ArtifactRootPath    string `json:"artifact_root_path"`
+	// Backend usage accumulated across all tasks of the run.
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+	// Failover counts the failover backends switched to; the current one is
+	// defaults.failover[Failover-1].
+	Failover int `json:"failover,omitempty"`
+	// Profile is the config profile the run was started with, "" for none;
+	// resuming applies it again unless --profile is given. Nil in state written
+	// before profiles were recorded.
+	Profile *string `json:"profile,omitempty"`
 }
@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"errors"
+
+	"github.com/yarlson/turbine/internal/atomicfile"
+	"github.com/yarlson/turbine/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the task file version written by this build.
+const SchemaVersion = 1
+
+// taskFileSchema upgrades task files written by older builds. A missing version
+// is left for Validate to reject.
+var taskFileSchema = schema.New("task.yaml", "version", SchemaVersion, 0)
+
+// decodeTaskFile migrates and decodes a task file document.
+func decodeTaskFile(data []byte, tf *TaskFile) error {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	doc, migrated, err := taskFileSchema.Migrate(doc)
+	if err != nil {
+		var newer *schema.NewerVersionError
+		if errors.As(err, &newer) {
+			return atomicfile.Unrecoverable(err)
+		}
+		return err
+	}
+	if migrated {
+		if data, err = yaml.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	*tf = TaskFile{}
+	return yaml.Unmarshal(data, tf)
+}
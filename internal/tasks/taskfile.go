@@ -2,8 +2,8 @@ package tasks
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/yarlson/turbine/internal/atomicfile"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,31 +16,54 @@ type TaskFile struct {
 
 // LoadTaskFile loads and validates a single task file.
 func LoadTaskFile(path string) (*TaskFile, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("read task file: %w", err)
-	}
+	return LoadTaskFileWithBackup(path, "")
+}
 
+// LoadTaskFileWithBackup loads and validates a task file, falling back to the
+// copy at backupPath (see SaveWithBackup) when the file is corrupt or invalid.
+func LoadTaskFileWithBackup(path, backupPath string) (*TaskFile, error) {
 	var tf TaskFile
-	if err := yaml.Unmarshal(data, &tf); err != nil {
-		return nil, fmt.Errorf("unmarshal task yaml: %w", err)
+	read := false
+	err := atomicfile.ReadFile(path, backupPath, func(data []byte) error {
+		read = true
+		return parseTaskFile(data, &tf)
+	})
+	if err != nil {
+		if !read {
+			return nil, fmt.Errorf("read task file: %w", err)
+		}
+		return nil, err
 	}
 
+	return &tf, nil
+}
+
+// parseTaskFile decodes and validates task file content.
+func parseTaskFile(data []byte, tf *TaskFile) error {
+	if err := decodeTaskFile(data, tf); err != nil {
+		return fmt.Errorf("unmarshal task yaml: %w", err)
+	}
 	if err := tf.Validate(); err != nil {
-		return nil, fmt.Errorf("validate task: %w", err)
+		return fmt.Errorf("validate task: %w", err)
 	}
-
-	return &tf, nil
+	return nil
 }
 
-// Save writes the task file to disk.
+// Save atomically writes the task file to disk.
 func (t *TaskFile) Save(path string) error {
+	return t.SaveWithBackup(path, "")
+}
+
+// SaveWithBackup atomically writes the task file, first copying the previous
+// version to backupPath if it is a valid task file.
+func (t *TaskFile) SaveWithBackup(path, backupPath string) error {
 	data, err := yaml.Marshal(t)
 	if err != nil {
 		return fmt.Errorf("marshal task yaml: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	check := func(data []byte) error { return parseTaskFile(data, &TaskFile{}) }
+	if err := atomicfile.WriteFile(path, data, 0644, backupPath, check); err != nil {
 		return fmt.Errorf("write task file: %w", err)
 	}
 
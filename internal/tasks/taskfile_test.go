@@ -52,3 +52,56 @@ task:
 		assert.ErrorContains(t, err, "invalid status")
 	})
 }
+
+func TestLoadTaskFileWithBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	taskPath := filepath.Join(tmpDir, "task.yaml")
+	backupPath := filepath.Join(tmpDir, "state", "task.yaml.bak")
+
+	tf := &TaskFile{
+		Version: 1,
+		Task:    Task{ID: "T-001", Title: "Task 1", Status: StatusTodo, Description: "desc", CommitMessage: "feat: task 1"},
+	}
+	require.NoError(t, tf.SaveWithBackup(taskPath, backupPath))
+	assert.NoFileExists(t, backupPath)
+
+	tf.Task.Status = StatusDone
+	require.NoError(t, tf.SaveWithBackup(taskPath, backupPath))
+	assert.FileExists(t, backupPath)
+
+	// A truncated write leaves the file without required fields.
+	require.NoError(t, os.WriteFile(taskPath, []byte("version: 1\ntask:\n  id: T-0"), 0644))
+
+	_, err := LoadTaskFile(taskPath)
+	assert.Error(t, err)
+
+	loaded, err := LoadTaskFileWithBackup(taskPath, backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, "T-001", loaded.Task.ID)
+	assert.Equal(t, StatusTodo, loaded.Task.Status)
+
+	// Saving over a corrupt file keeps the last good backup.
+	require.NoError(t, os.WriteFile(taskPath, []byte("version: 1\ntask:\n  id: T-0"), 0644))
+	require.NoError(t, tf.SaveWithBackup(taskPath, backupPath))
+	backup, err := LoadTaskFile(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, StatusTodo, backup.Task.Status)
+}
+
+func TestLoadTaskFileNewerVersion(t *testing.T) {
+	taskPath := filepath.Join(t.TempDir(), "task.yaml")
+	content := `
+version: 2
+task:
+  id: T-001
+  title: Task 1
+  status: todo
+  commit_message: "feat: task 1"
+`
+	require.NoError(t, os.WriteFile(taskPath, []byte(content), 0644))
+
+	_, err := LoadTaskFile(taskPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 2")
+	assert.Contains(t, err.Error(), "supports up to version 1")
+}
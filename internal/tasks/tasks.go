@@ -24,6 +24,7 @@ type Task struct {
 	Acceptance    []string               `yaml:"acceptance"`
 	Verify        []string               `yaml:"verify"`
 	CommitMessage string                 `yaml:"commit_message"`
+	Section       string                 `yaml:"section,omitempty"`
 	Other         map[string]interface{} `yaml:",inline"`
 }
 